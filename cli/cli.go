@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"golang-rest-user/provider/mySqlProvider"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/provider/tenantProvider"
+	"os"
+)
+
+// Run executes a one-off command instead of starting the HTTP server and
+// returns the process exit code.
+func Run(args []string) int {
+	switch args[0] {
+	case "tenant":
+		return tenantCommand(args[1:])
+	case "user":
+		return userCommand(args[1:])
+	case "keys":
+		return keysCommand(args[1:])
+	case "migrate":
+		return migrate(args[1:])
+	case "health":
+		return health(args[1:])
+	case "password-report":
+		return passwordReport(args[1:])
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+}
+
+// bootstrap wires the services the same way the server does, but only binds
+// the tenant registry instead of connecting every tenant.
+func bootstrap() {
+	redisProvider.Init()
+	mySqlProvider.Init()
+	serviceProvider.Init()
+	tenantProvider.Bind()
+}
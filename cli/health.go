@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/mySqlProvider"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/provider/tenantProvider"
+	"golang-rest-user/utils"
+	"time"
+)
+
+type componentHealth struct {
+	OK        bool   `json:"ok"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type tenantHealth struct {
+	Code          string             `json:"code"`
+	Status        enums.TenantStatus `json:"status"`
+	OK            bool               `json:"ok"`
+	LatencyMs     int64              `json:"latency_ms"`
+	SchemaVersion int                `json:"schema_version"`
+	SchemaLatest  int                `json:"schema_latest"`
+	SchemaDirty   bool               `json:"schema_dirty"`
+	Error         string             `json:"error,omitempty"`
+}
+
+type healthReport struct {
+	OK      bool            `json:"ok"`
+	Master  componentHealth `json:"master"`
+	Redis   componentHealth `json:"redis"`
+	Tenants []tenantHealth  `json:"tenants"`
+}
+
+func check(fn func() error) componentHealth {
+	start := time.Now()
+	err := fn()
+	result := componentHealth{OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// health connects to the master DB, Redis and every tenant DB and reports
+// what is reachable. Suspended tenants are checked as well; tenants that are
+// provisioning or failed are reported without connecting.
+func health(args []string) int {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	concurrency := fs.Int("concurrency", 8, "tenants checked in parallel")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	report := healthReport{Tenants: []tenantHealth{}}
+	report.Redis = check(redisProvider.Connect)
+	report.Master = check(mySqlProvider.Connect)
+
+	if report.Master.OK {
+		serviceProvider.Init()
+		tenants, err := serviceProvider.GetInstance().TenantService.ListAllTenantConnect()
+		if err != nil {
+			report.Master = componentHealth{Error: err.Error()}
+		}
+		report.Tenants = make([]tenantHealth, len(tenants))
+		utils.ParallelEach(len(tenants), *concurrency, func(i int) {
+			tenant := &tenants[i]
+			item := &report.Tenants[i]
+			item.Code = tenant.Code
+			item.Status = tenant.Status
+			if tenant.Status == enums.TenantStatusProvisioning || tenant.Status == enums.TenantStatusFailed {
+				item.Error = "tenant is " + string(tenant.Status)
+				return
+			}
+
+			start := time.Now()
+			info, err := tenantProvider.Open(tenant)
+			item.LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				item.Error = err.Error()
+				return
+			}
+			defer info.Destruction()
+
+			migrator, err := info.Migrator()
+			if err == nil {
+				status, statusErr := migrator.Status(context.Background())
+				if err = statusErr; err == nil {
+					item.SchemaVersion = status.Version
+					item.SchemaLatest = status.Latest
+					item.SchemaDirty = status.Dirty
+				}
+			}
+			if err != nil {
+				item.Error = err.Error()
+				return
+			}
+			item.OK = !item.SchemaDirty && item.SchemaVersion == item.SchemaLatest
+			if !item.OK {
+				item.Error = fmt.Sprintf("schema at version %d of %d", item.SchemaVersion, item.SchemaLatest)
+			}
+		})
+	}
+
+	report.OK = report.Master.OK && report.Redis.OK
+	for _, t := range report.Tenants {
+		if !t.OK && t.Status == enums.TenantStatusActive {
+			report.OK = false
+		}
+	}
+
+	exitCode := 0
+	if !report.OK {
+		exitCode = 1
+	}
+	if *jsonOut {
+		printJSON(report)
+		return exitCode
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "TARGET\tSTATUS\tLATENCY\tSCHEMA\tRESULT")
+	printComponent := func(name string, c componentHealth) {
+		fmt.Fprintf(w, "%s\t-\t%dms\t-\t%s\n", name, c.LatencyMs, resultText(c.OK, c.Error))
+	}
+	printComponent("(master)", report.Master)
+	printComponent("(redis)", report.Redis)
+	for _, t := range report.Tenants {
+		fmt.Fprintf(w, "%s\t%s\t%dms\t%d/%d\t%s\n", t.Code, t.Status, t.LatencyMs, t.SchemaVersion, t.SchemaLatest, resultText(t.OK, t.Error))
+	}
+	_ = w.Flush()
+	return exitCode
+}
+
+func resultText(ok bool, err string) string {
+	if ok {
+		return "ok"
+	}
+	return err
+}
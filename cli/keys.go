@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"golang-rest-user/provider/mySqlProvider"
+	"golang-rest-user/provider/serviceProvider"
+	"os"
+)
+
+func keysCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: keys rotate|list [flags]")
+		return 2
+	}
+	switch args[0] {
+	case "rotate":
+		return keysRotate(args[1:])
+	case "list":
+		return keysList(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown keys command %q\n", args[0])
+		return 2
+	}
+}
+
+// keysRotate creates a new active JWT signing key and retires the current
+// one. Running servers pick it up on their next key reload.
+func keysRotate(args []string) int {
+	fs := flag.NewFlagSet("keys rotate", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	algorithm := fs.String("algorithm", os.Getenv("JWT_SIGNING_ALGORITHM"), "RS256, ES256 or EdDSA")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	mySqlProvider.Init()
+	serviceProvider.Init()
+	key, err := serviceProvider.GetInstance().SigningKeyService.Rotate(*algorithm)
+	if err != nil {
+		return fail(*jsonOut, fmt.Errorf("rotate %q: %w", *algorithm, err))
+	}
+	if *jsonOut {
+		printJSON(key)
+		return 0
+	}
+	fmt.Printf("new %s signing key %s is active\n", key.Algorithm, key.Kid)
+	return 0
+}
+
+func keysList(args []string) int {
+	fs := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	mySqlProvider.Init()
+	serviceProvider.Init()
+	keys, err := serviceProvider.GetInstance().SigningKeyService.List()
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(keys)
+		return 0
+	}
+	w := newTable()
+	fmt.Fprintln(w, "KID\tALGORITHM\tSTATUS\tCREATED_AT\tRETIRED_AT")
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", k.Kid, k.Algorithm, k.Status, k.CreatedAt, k.RetiredAt)
+	}
+	_ = w.Flush()
+	return 0
+}
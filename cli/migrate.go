@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-rest-user/enums"
+	"golang-rest-user/migration"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/mySqlProvider"
+	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/provider/tenantProvider"
+	"golang-rest-user/utils"
+	"os"
+	"strings"
+)
+
+type migrateResult struct {
+	target string
+	result *migration.Result
+	err    error
+}
+
+// migrate applies (or reverts) schema migrations on the master DB and on
+// every tenant DB, at most -concurrency tenants at a time.
+//
+//	migrate [-scope all|master|tenants] [-tenant CODE] [-concurrency 4] [-down N] [-force VERSION]
+func migrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	scope := fs.String("scope", "all", "all, master or tenants")
+	tenantCode := fs.String("tenant", "", "only migrate this tenant")
+	concurrency := fs.Int("concurrency", 4, "tenants migrated in parallel")
+	down := fs.Int("down", 0, "revert this many migrations instead of migrating up")
+	force := fs.Int("force", -1, "mark this version as clean without running SQL")
+	jsonOut := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *scope != "all" && *scope != "master" && *scope != "tenants" {
+		fmt.Fprintf(os.Stderr, "invalid scope %q\n", *scope)
+		return 2
+	}
+
+	run := func(migrator *migration.Migrator) (*migration.Result, error) {
+		ctx := context.Background()
+		switch {
+		case *force >= 0:
+			if err := migrator.Force(ctx, *force); err != nil {
+				return nil, err
+			}
+			return &migration.Result{To: *force, Applied: []int{}}, nil
+		case *down > 0:
+			return migrator.Down(ctx, *down)
+		default:
+			return migrator.Up(ctx)
+		}
+	}
+
+	if err := mySqlProvider.Connect(); err != nil {
+		return fail(*jsonOut, err)
+	}
+	var results []migrateResult
+
+	if *scope != "tenants" && *tenantCode == "" {
+		r := migrateResult{target: "(master)"}
+		migrator, err := mySqlProvider.Migrator()
+		if err == nil {
+			r.result, r.err = run(migrator)
+		} else {
+			r.err = err
+		}
+		results = append(results, r)
+		if r.err != nil {
+			printMigrateResults(results, *jsonOut)
+			return 1
+		}
+	}
+
+	if *scope != "master" {
+		serviceProvider.Init()
+		tenants, err := serviceProvider.GetInstance().TenantService.ListAllTenantConnect()
+		if err != nil {
+			return fail(*jsonOut, err)
+		}
+		if *tenantCode != "" {
+			tenants = filterTenants(tenants, *tenantCode)
+			if len(tenants) == 0 {
+				return fail(*jsonOut, fmt.Errorf("tenant %q not found", *tenantCode))
+			}
+		}
+
+		tenantResults := make([]migrateResult, len(tenants))
+		utils.ParallelEach(len(tenants), *concurrency, func(i int) {
+			tenant := &tenants[i]
+			tenantResults[i] = migrateResult{target: tenant.Code}
+			if tenant.Status == enums.TenantStatusProvisioning || tenant.Status == enums.TenantStatusFailed {
+				tenantResults[i].err = errors.New("skipped: tenant is " + string(tenant.Status))
+				return
+			}
+			info, err := tenantProvider.Open(tenant)
+			if err != nil {
+				tenantResults[i].err = err
+				return
+			}
+			defer info.Destruction()
+			migrator, err := info.Migrator()
+			if err != nil {
+				tenantResults[i].err = err
+				return
+			}
+			tenantResults[i].result, tenantResults[i].err = run(migrator)
+		})
+		results = append(results, tenantResults...)
+	}
+
+	return printMigrateResults(results, *jsonOut)
+}
+
+func filterTenants(tenants []models.Tenant, code string) []models.Tenant {
+	for _, t := range tenants {
+		if t.Code == code {
+			return []models.Tenant{t}
+		}
+	}
+	return nil
+}
+
+type migrateOutput struct {
+	Target  string `json:"target"`
+	From    *int   `json:"from"`
+	To      *int   `json:"to"`
+	Applied []int  `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+func printMigrateResults(results []migrateResult, jsonOut bool) int {
+	exitCode := 0
+	output := make([]migrateOutput, 0, len(results))
+	for _, r := range results {
+		item := migrateOutput{Target: r.target, Applied: []int{}}
+		if r.result != nil {
+			item.From, item.To, item.Applied = &r.result.From, &r.result.To, r.result.Applied
+		}
+		if r.err != nil {
+			item.Error = r.err.Error()
+			exitCode = 1
+		}
+		output = append(output, item)
+	}
+	if jsonOut {
+		printJSON(output)
+		return exitCode
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "TARGET\tFROM\tTO\tAPPLIED\tRESULT")
+	for _, item := range output {
+		from, to := "-", "-"
+		if item.From != nil {
+			from, to = fmt.Sprint(*item.From), fmt.Sprint(*item.To)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Target, from, to, joinVersions(item.Applied), resultText(item.Error == "", item.Error))
+	}
+	_ = w.Flush()
+	return exitCode
+}
+
+func joinVersions(versions []int) string {
+	if len(versions) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ",")
+}
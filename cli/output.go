@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// fail reports err in the requested format and returns exit code 1.
+func fail(jsonOut bool, err error) int {
+	if jsonOut {
+		printJSON(map[string]string{"error": err.Error()})
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return 1
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, `usage: golang-rest-user <command> [flags]
+
+commands:
+  tenant create|list|suspend|delete   manage tenants
+  user create-admin|reset-password|revoke-sessions|set-role
+                                      manage users of a tenant
+  keys rotate|list                    manage JWT signing keys
+  migrate                             apply schema migrations
+  health                              check master DB, Redis and every tenant
+  password-report                     count legacy encrypted passwords
+
+every command accepts -json for machine readable output
+
+tenants that existed before tenant roles have no tenant_admin; assign one with
+  user set-role -tenant <code> -username <name> [-role tenant_admin]
+`)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/provider/tenantProvider"
+	"sort"
+)
+
+// passwordReport prints how many users per tenant still have an AES-GCM
+// encrypted password, i.e. have not logged in since hashing was introduced.
+func passwordReport(args []string) int {
+	fs := flag.NewFlagSet("password-report", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	bootstrap()
+	tenants, err := serviceProvider.GetInstance().TenantService.ListAllTenantConnect()
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Code < tenants[j].Code })
+
+	type row struct {
+		Tenant string `json:"tenant"`
+		Legacy int64  `json:"legacy"`
+		Total  int64  `json:"total"`
+		Error  string `json:"error,omitempty"`
+	}
+	rows := make([]row, 0, len(tenants))
+	exitCode := 0
+	for i := range tenants {
+		if tenants[i].Status == enums.TenantStatusProvisioning || tenants[i].Status == enums.TenantStatusFailed {
+			continue
+		}
+		r := row{Tenant: tenants[i].Code}
+		info, err := tenantProvider.Open(&tenants[i])
+		if err == nil {
+			r.Legacy, r.Total, err = info.UserService.CountLegacyPasswords()
+			info.Destruction()
+		}
+		if err != nil {
+			r.Error = err.Error()
+			exitCode = 1
+		}
+		rows = append(rows, r)
+	}
+	if *jsonOut {
+		printJSON(rows)
+		return exitCode
+	}
+
+	w := newTable()
+	fmt.Fprintln(w, "TENANT\tLEGACY\tTOTAL")
+	for _, r := range rows {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\terror: %s\t\n", r.Tenant, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\n", r.Tenant, r.Legacy, r.Total)
+	}
+	_ = w.Flush()
+	return exitCode
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/serviceProvider"
+	"os"
+	"time"
+)
+
+func tenantCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: tenant create|list|suspend|delete [flags]")
+		return 2
+	}
+	switch args[0] {
+	case "create":
+		return tenantCreate(args[1:])
+	case "list":
+		return tenantList(args[1:])
+	case "suspend":
+		return tenantSuspend(args[1:])
+	case "delete":
+		return tenantDelete(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown tenant command %q\n", args[0])
+		return 2
+	}
+}
+
+// tenantCreate creates the tenant and runs its provisioning job in this
+// process, waiting until the job has finished.
+//
+// The database credentials default to the master DB's DB_* variables.
+func tenantCreate(args []string) int {
+	fs := flag.NewFlagSet("tenant create", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	var req dto.CreateTenantRequest
+	fs.StringVar(&req.Code, "code", "", "tenant code (required)")
+	fs.StringVar(&req.Name, "name", "", "tenant name (required)")
+	fs.StringVar(&req.DBName, "db-name", "", "database name (required)")
+	fs.StringVar(&req.DBUser, "db-user", os.Getenv("DB_USER"), "database user")
+	fs.StringVar(&req.DBPass, "db-pass", os.Getenv("DB_PASS"), "database password")
+	fs.StringVar(&req.DBHost, "db-host", os.Getenv("DB_HOST"), "database host")
+	fs.StringVar(&req.DBPort, "db-port", os.Getenv("DB_PORT"), "database port")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if req.Code == "" || req.Name == "" || req.DBName == "" || req.DBUser == "" || req.DBHost == "" || req.DBPort == "" {
+		fmt.Fprintln(os.Stderr, "-code, -name, -db-name, -db-user, -db-host and -db-port are required")
+		return 2
+	}
+
+	bootstrap()
+	appService := serviceProvider.GetInstance()
+	created, err := appService.TenantService.Create(req)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+
+	var job *dto.ProvisioningResponse
+	for {
+		job, err = appService.ProvisioningService.Get(created.ProvisioningID)
+		if err != nil {
+			return fail(*jsonOut, err)
+		}
+		if job.Status == enums.ProvisioningSucceeded || job.Status == enums.ProvisioningFailed {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	exitCode := 0
+	if job.Status == enums.ProvisioningFailed {
+		exitCode = 1
+	}
+	if *jsonOut {
+		printJSON(map[string]interface{}{
+			"tenant":       created.Tenant,
+			"provisioning": job,
+		})
+		return exitCode
+	}
+	w := newTable()
+	fmt.Fprintln(w, "STEP\tSTATUS\tERROR")
+	for _, step := range job.Steps {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", step.Name, step.Status, step.Error)
+	}
+	_ = w.Flush()
+	fmt.Printf("tenant %s: provisioning %s (%s)\n", created.Tenant.Code, job.Status, job.UUID)
+	return exitCode
+}
+
+func tenantList(args []string) int {
+	fs := flag.NewFlagSet("tenant list", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	page := fs.Int("page", 1, "page")
+	pageSize := fs.Int("page-size", 100, "page size")
+	search := fs.String("search", "", "filter by code or name")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	bootstrap()
+	tenants, total, err := serviceProvider.GetInstance().TenantService.List(*page, *pageSize, *search)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	if tenants == nil {
+		tenants = []dto.TenantResponse{}
+	}
+	if *jsonOut {
+		printJSON(map[string]interface{}{
+			"items":     tenants,
+			"page":      *page,
+			"page_size": *pageSize,
+			"total":     total,
+		})
+		return 0
+	}
+	w := newTable()
+	fmt.Fprintln(w, "CODE\tNAME\tSTATUS\tDB_HOST\tDB_NAME\tCREATED_AT")
+	for _, t := range tenants {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Code, t.Name, t.Status, t.DBHost, t.DBName, t.CreatedAt)
+	}
+	_ = w.Flush()
+	fmt.Printf("%d of %d tenants\n", len(tenants), total)
+	return 0
+}
+
+func tenantSuspend(args []string) int {
+	fs := flag.NewFlagSet("tenant suspend", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	code := fs.String("code", "", "tenant code (required)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *code == "" {
+		return fail(*jsonOut, errors.New("-code is required"))
+	}
+
+	bootstrap()
+	tenant, err := serviceProvider.GetInstance().TenantService.Suspend(*code)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(tenant)
+		return 0
+	}
+	fmt.Printf("tenant %s suspended\n", tenant.Code)
+	return 0
+}
+
+func tenantDelete(args []string) int {
+	fs := flag.NewFlagSet("tenant delete", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	code := fs.String("code", "", "tenant code (required)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *code == "" {
+		return fail(*jsonOut, errors.New("-code is required"))
+	}
+
+	bootstrap()
+	if err := serviceProvider.GetInstance().TenantService.Delete(*code); err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(map[string]string{"code": *code, "status": "deleted"})
+		return 0
+	}
+	fmt.Printf("tenant %s deleted\n", *code)
+	return 0
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/provider/tenantProvider"
+	"os"
+)
+
+func userCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: user create-admin|reset-password|revoke-sessions|set-role [flags]")
+		return 2
+	}
+	switch args[0] {
+	case "create-admin":
+		return userCreateAdmin(args[1:])
+	case "reset-password":
+		return userResetPassword(args[1:])
+	case "revoke-sessions":
+		return userRevokeSessions(args[1:])
+	case "set-role":
+		return userSetRole(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown user command %q\n", args[0])
+		return 2
+	}
+}
+
+// openTenant connects to the tenant's database directly; the caller must
+// call Destruction on the result.
+func openTenant(code string) (*tenantProvider.TenantInfo, error) {
+	bootstrap()
+	tenant, err := serviceProvider.GetInstance().TenantService.GetTenantConnect(code)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", code, err)
+	}
+	if tenant.Status == enums.TenantStatusProvisioning || tenant.Status == enums.TenantStatusFailed {
+		return nil, fmt.Errorf("tenant %s is %s", code, tenant.Status)
+	}
+	return tenantProvider.Open(tenant)
+}
+
+// userCreateAdmin creates the first user of a freshly provisioned tenant.
+func userCreateAdmin(args []string) int {
+	fs := flag.NewFlagSet("user create-admin", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	tenantCode := fs.String("tenant", "", "tenant code (required)")
+	var req dto.CreateUserRequest
+	fs.StringVar(&req.Username, "username", "", "username (required)")
+	fs.StringVar(&req.Password, "password", "", "password (required, at least 6 characters)")
+	fs.StringVar(&req.FullName, "full-name", "Administrator", "full name")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *tenantCode == "" || req.Username == "" {
+		return fail(*jsonOut, errors.New("-tenant and -username are required"))
+	}
+	if len(req.Password) < 6 {
+		return fail(*jsonOut, errors.New("-password must be at least 6 characters"))
+	}
+	req.Role = string(enums.TenantRoleAdmin)
+
+	info, err := openTenant(*tenantCode)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	defer info.Destruction()
+
+	if _, total, err := info.UserService.List(1, 1, ""); err != nil {
+		return fail(*jsonOut, err)
+	} else if total > 0 {
+		return fail(*jsonOut, fmt.Errorf("tenant %s already has users", *tenantCode))
+	}
+	user, err := info.UserService.Create(req)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(user)
+		return 0
+	}
+	fmt.Printf("user %s created in tenant %s (%s)\n", user.Username, *tenantCode, user.UUID)
+	return 0
+}
+
+func userResetPassword(args []string) int {
+	fs := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	tenantCode := fs.String("tenant", "", "tenant code (required)")
+	username := fs.String("username", "", "username (required)")
+	password := fs.String("password", "", "new password (required, at least 6 characters)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *tenantCode == "" || *username == "" {
+		return fail(*jsonOut, errors.New("-tenant and -username are required"))
+	}
+	if len(*password) < 6 {
+		return fail(*jsonOut, errors.New("-password must be at least 6 characters"))
+	}
+
+	info, err := openTenant(*tenantCode)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	defer info.Destruction()
+
+	if err := info.UserService.ResetPassword(*username, *password); err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(map[string]string{"tenant": *tenantCode, "username": *username, "status": "password_reset"})
+		return 0
+	}
+	fmt.Printf("password of %s reset, all sessions revoked\n", *username)
+	return 0
+}
+
+func userRevokeSessions(args []string) int {
+	fs := flag.NewFlagSet("user revoke-sessions", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	tenantCode := fs.String("tenant", "", "tenant code (required)")
+	username := fs.String("username", "", "username (required)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *tenantCode == "" || *username == "" {
+		return fail(*jsonOut, errors.New("-tenant and -username are required"))
+	}
+
+	info, err := openTenant(*tenantCode)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	defer info.Destruction()
+
+	if err := info.UserService.RevokeSessions(*username); err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(map[string]string{"tenant": *tenantCode, "username": *username, "status": "sessions_revoked"})
+		return 0
+	}
+	fmt.Printf("all sessions of %s revoked\n", *username)
+	return 0
+}
+
+// userSetRole changes a user's tenant role, e.g. to appoint the first admin
+// of a tenant created before roles existed, or to recover a tenant whose
+// admins are all gone.
+func userSetRole(args []string) int {
+	fs := flag.NewFlagSet("user set-role", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "print JSON")
+	tenantCode := fs.String("tenant", "", "tenant code (required)")
+	username := fs.String("username", "", "username (required)")
+	role := fs.String("role", string(enums.TenantRoleAdmin), "tenant_admin or member")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *tenantCode == "" || *username == "" {
+		return fail(*jsonOut, errors.New("-tenant and -username are required"))
+	}
+
+	info, err := openTenant(*tenantCode)
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	defer info.Destruction()
+
+	user, err := info.UserService.GetByUsername(*username)
+	if err != nil {
+		return fail(*jsonOut, fmt.Errorf("user %s: %w", *username, err))
+	}
+	user, err = info.UserService.SetRole(user.UUID, enums.TenantRole(*role))
+	if err != nil {
+		return fail(*jsonOut, err)
+	}
+	if *jsonOut {
+		printJSON(user)
+		return 0
+	}
+	fmt.Printf("%s is now %s in tenant %s\n", user.Username, user.Role, *tenantCode)
+	return 0
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	TenantResolverHeader    = "header"
+	TenantResolverSubdomain = "subdomain"
+	TenantResolverPath      = "path"
+	TenantResolverJWT       = "jwt"
+)
+
+type TenantResolverConfig struct {
+	// Strategies are tried in order, the first one that yields a code wins.
+	Strategies []string
+	// BaseDomain is the domain under which tenants get a subdomain,
+	// e.g. api.example.com for acme.api.example.com.
+	BaseDomain string
+}
+
+func LoadTenantResolverConfig() TenantResolverConfig {
+	raw := os.Getenv("TENANT_RESOLVERS")
+	if raw == "" {
+		raw = TenantResolverHeader + "," + TenantResolverJWT
+	}
+	var strategies []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(strings.ToLower(s)); s != "" {
+			strategies = append(strategies, s)
+		}
+	}
+	return TenantResolverConfig{
+		Strategies: strategies,
+		BaseDomain: strings.ToLower(os.Getenv("TENANT_BASE_DOMAIN")),
+	}
+}
+
+func (c TenantResolverConfig) Has(strategy string) bool {
+	for _, s := range c.Strategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "golang-rest-user/enums"
+
+type OperatorLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type CreateOperatorRequest struct {
+	Username string             `json:"username" binding:"required"`
+	Password string             `json:"password" binding:"required,min=8"`
+	FullName string             `json:"full_name" binding:"omitempty"`
+	Role     enums.OperatorRole `json:"role" binding:"required"`
+}
+
+type UpdateOperatorRequest struct {
+	FullName string             `json:"full_name" binding:"omitempty"`
+	Role     enums.OperatorRole `json:"role" binding:"required"`
+}
+
+type OperatorResponse struct {
+	UUID      string             `json:"uuid"`
+	Username  string             `json:"username"`
+	FullName  string             `json:"full_name"`
+	Role      enums.OperatorRole `json:"role"`
+	CreatedAt string             `json:"created_at"`
+	UpdatedAt string             `json:"updated_at"`
+}
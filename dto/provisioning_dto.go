@@ -0,0 +1,28 @@
+package dto
+
+import "golang-rest-user/enums"
+
+type ProvisioningStepResponse struct {
+	Attempt    int                      `json:"attempt"`
+	Name       enums.ProvisioningStep   `json:"name"`
+	Status     enums.ProvisioningStatus `json:"status"`
+	Error      string                   `json:"error,omitempty"`
+	StartedAt  string                   `json:"started_at"`
+	FinishedAt string                   `json:"finished_at,omitempty"`
+}
+
+type ProvisioningResponse struct {
+	UUID       string                     `json:"uuid"`
+	TenantCode string                     `json:"tenant_code"`
+	Status     enums.ProvisioningStatus   `json:"status"`
+	Attempt    int                        `json:"attempt"`
+	Error      string                     `json:"error,omitempty"`
+	StartedAt  string                     `json:"started_at,omitempty"`
+	FinishedAt string                     `json:"finished_at,omitempty"`
+	Steps      []ProvisioningStepResponse `json:"steps"`
+}
+
+type CreateTenantResponse struct {
+	Tenant         *TenantResponse `json:"tenant"`
+	ProvisioningID string          `json:"provisioning_id"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "golang-rest-user/enums"
+
+type SchemaStatus struct {
+	Version int  `json:"version"`
+	Latest  int  `json:"latest"`
+	Pending int  `json:"pending"`
+	Dirty   bool `json:"dirty"`
+}
+
+type TenantSchemaResponse struct {
+	Code  string            `json:"code"`
+	State enums.TenantState `json:"state"`
+	SchemaStatus
+	Error string `json:"error,omitempty"`
+}
+
+type SchemaVersionsResponse struct {
+	Master  SchemaStatus           `json:"master"`
+	Tenants []TenantSchemaResponse `json:"tenants"`
+}
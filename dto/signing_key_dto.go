@@ -0,0 +1,11 @@
+package dto
+
+import "golang-rest-user/enums"
+
+type SigningKeyResponse struct {
+	Kid       string                 `json:"kid"`
+	Algorithm string                 `json:"algorithm"`
+	Status    enums.SigningKeyStatus `json:"status"`
+	CreatedAt string                 `json:"created_at"`
+	RetiredAt string                 `json:"retired_at,omitempty"`
+}
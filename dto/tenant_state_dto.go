@@ -0,0 +1,11 @@
+package dto
+
+import "golang-rest-user/enums"
+
+type TenantStateResponse struct {
+	Code      string            `json:"code"`
+	State     enums.TenantState `json:"state"`
+	InFlight  int64             `json:"in_flight"`
+	Error     string            `json:"error,omitempty"`
+	UpdatedAt string            `json:"updated_at"`
+}
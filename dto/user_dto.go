@@ -6,15 +6,37 @@ type CreateUserRequest struct {
 	FullName string `json:"full_name" binding:"required"`
 	Phone    string `json:"phone" binding:"omitempty"`
 	Position string `json:"position" binding:"omitempty"`
+	// Role defaults to member. Ignored on self-registration.
+	Role string `json:"role" binding:"omitempty"`
 }
 
 type LoginRequest struct {
-	Username string `json:"username" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username   string `json:"username" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=6"`
+	DeviceName string `json:"device_name" binding:"omitempty,max=100"`
 }
 
 type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	// All ends every session of the user instead of only this one.
+	All bool `json:"all"`
+}
+
+type ForgotPasswordRequest struct {
+	Username string `json:"username" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Username string `json:"username" binding:"required,email"`
 }
 
 type RefreshTokenRequest struct {
@@ -27,13 +49,27 @@ type UpdateUserRequest struct {
 	Position string `json:"position" binding:"omitempty"`
 }
 
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6,nefield=CurrentPassword"`
+}
+
 type UserResponse struct {
-	ID        uint   `json:"id"`
-	UUID      string `json:"uuid"`
-	Username  string `json:"username"`
-	FullName  string `json:"fullname"`
-	Phone     string `json:"phone"`
-	Position  string `json:"position"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID            uint   `json:"id"`
+	UUID          string `json:"uuid"`
+	Username      string `json:"username"`
+	FullName      string `json:"fullname"`
+	Phone         string `json:"phone"`
+	Position      string `json:"position"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+	MFAEnabled    bool   `json:"mfa_enabled"`
+	Locked        bool   `json:"locked"`
+	LockedUntil   string `json:"locked_until,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
 }
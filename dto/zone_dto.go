@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"golang-rest-user/enums"
 	"time"
 
 	"gorm.io/datatypes"
@@ -13,6 +14,10 @@ type ZoneDTORequest struct {
 	ParentID *uint          `json:"parent_id"`
 }
 
+type MoveZoneRequest struct {
+	ParentID uint `json:"parent_id" binding:"required"`
+}
+
 type ZoneDTOResponse struct {
 	ID        uint           `json:"id"`
 	UUID      string         `json:"uuid"`
@@ -24,4 +29,61 @@ type ZoneDTOResponse struct {
 	CreatedAt time.Time      `Gorm:"type:datetime"`
 	UpdatedAt time.Time      `Gorm:"type:datetime"`
 	ParentID  *uint          `json:"parent_id"`
+	// Permission is the caller's effective role on the zone, set on listings.
+	Permission enums.UserPermission `json:"permission,omitempty"`
+	// Breadcrumb runs from the topmost zone the caller can see down to this one.
+	Breadcrumb []ZoneBreadcrumb `json:"breadcrumb,omitempty"`
+}
+
+type ZoneBreadcrumb struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Level int    `json:"level"`
+}
+
+// ZoneTreeOptions shapes GET /zones/:uuid/tree. Depth counts levels below
+// the requested zone. Lazy returns the zone and its direct children only,
+// each with a child count, so a client can expand the tree on demand.
+type ZoneTreeOptions struct {
+	Depth           int
+	IncludeMetadata bool
+	Fields          []string
+	Lazy            bool
+}
+
+// ZoneTreeNode is a zone with the requested fields, and "children" unless the
+// node sits on the depth limit.
+type ZoneTreeNode map[string]interface{}
+
+// UserZonesSummary is what the caller owns and what was shared with them.
+type UserZonesSummary struct {
+	OwnedCount  int `json:"owned_count"`
+	SharedCount int `json:"shared_count"`
+	// SharedByPermission counts shared zones per permission, e.g. "viewer".
+	SharedByPermission map[string]int     `json:"shared_by_permission"`
+	Owned              []ZoneGrantSummary `json:"owned"`
+	Shared             []ZoneGrantSummary `json:"shared"`
+}
+
+type ZoneGrantSummary struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Permission  string `json:"permission"`
+	Descendants int64  `json:"descendants"`
+}
+
+// TrashedZoneResponse is a deleted subtree. Zones counts the zones a restore
+// brings back; it can only be restored while its parent exists.
+type TrashedZoneResponse struct {
+	UUID       string    `json:"uuid"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Path       string    `json:"path"`
+	ParentID   *uint     `json:"parent_id"`
+	DeletedBy  *uint     `json:"deleted_by"`
+	TrashedAt  time.Time `json:"trashed_at"`
+	Zones      int64     `json:"zones"`
+	Restorable bool      `json:"restorable"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+import "golang-rest-user/enums"
+
+type CreateZoneRoleRequest struct {
+	Name        string             `json:"name" binding:"required,max=50"`
+	Description string             `json:"description" binding:"omitempty,max=255"`
+	Actions     []enums.ZoneAction `json:"actions" binding:"required,min=1"`
+}
+
+type UpdateZoneRoleRequest struct {
+	Description string             `json:"description" binding:"omitempty,max=255"`
+	Actions     []enums.ZoneAction `json:"actions" binding:"required,min=1"`
+}
+
+type ZoneRoleResponse struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Actions     []enums.ZoneAction `json:"actions"`
+	BuiltIn     bool               `json:"built_in"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type CreateZoneTypeRequest struct {
+	Name           string         `json:"name" binding:"required,max=100"`
+	Description    string         `json:"description" binding:"omitempty,max=255"`
+	MetadataSchema datatypes.JSON `json:"metadata_schema"`
+	AllowedParents []string       `json:"allowed_parents"`
+	// AllowRoot defaults to true.
+	AllowRoot *bool `json:"allow_root"`
+	MaxDepth  int   `json:"max_depth" binding:"min=0"`
+}
+
+type UpdateZoneTypeRequest struct {
+	Description    string         `json:"description" binding:"omitempty,max=255"`
+	MetadataSchema datatypes.JSON `json:"metadata_schema"`
+	AllowedParents []string       `json:"allowed_parents"`
+	AllowRoot      *bool          `json:"allow_root"`
+	MaxDepth       int            `json:"max_depth" binding:"min=0"`
+}
+
+type ZoneTypeResponse struct {
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	MetadataSchema datatypes.JSON `json:"metadata_schema"`
+	AllowedParents []string       `json:"allowed_parents"`
+	AllowRoot      bool           `json:"allow_root"`
+	MaxDepth       int            `json:"max_depth"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// FieldError is one invalid field of a request, e.g. "metadata.address.zip".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -3,8 +3,11 @@ package enums
 type HandleTenant int
 
 const (
-	AddTenantConnect    HandleTenant = 1
-	EditTenantConnect   HandleTenant = 2
-	DeleteTenantConnect HandleTenant = 3
-	DropTenantConnect   HandleTenant = 4
+	AddTenantConnect      HandleTenant = 1
+	EditTenantConnect     HandleTenant = 2
+	DeleteTenantConnect   HandleTenant = 3
+	DropTenantConnect     HandleTenant = 4
+	SuspendTenantConnect  HandleTenant = 5
+	ResumeTenantConnect   HandleTenant = 6
+	SettingsTenantConnect HandleTenant = 7
 )
@@ -0,0 +1,17 @@
+package enums
+
+type OperatorRole string
+
+const (
+	OperatorRoleAdmin    OperatorRole = "admin"
+	OperatorRoleReadOnly OperatorRole = "read_only"
+)
+
+func (r OperatorRole) IsValid() bool {
+	switch r {
+	case OperatorRoleAdmin, OperatorRoleReadOnly:
+		return true
+	default:
+		return false
+	}
+}
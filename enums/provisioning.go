@@ -0,0 +1,20 @@
+package enums
+
+type ProvisioningStatus string
+
+const (
+	ProvisioningPending   ProvisioningStatus = "pending"
+	ProvisioningRunning   ProvisioningStatus = "running"
+	ProvisioningSucceeded ProvisioningStatus = "succeeded"
+	ProvisioningFailed    ProvisioningStatus = "failed"
+)
+
+type ProvisioningStep string
+
+const (
+	ProvisioningStepCreateDatabase ProvisioningStep = "create_database"
+	ProvisioningStepConnect        ProvisioningStep = "connect"
+	ProvisioningStepMigrate        ProvisioningStep = "migrate"
+	ProvisioningStepSeed           ProvisioningStep = "seed"
+	ProvisioningStepRollback       ProvisioningStep = "rollback"
+)
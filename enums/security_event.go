@@ -0,0 +1,13 @@
+package enums
+
+type SecurityEventType string
+
+const (
+	SecurityEventRefreshTokenReuse SecurityEventType = "refresh_token_reuse"
+	SecurityEventAccountLocked     SecurityEventType = "account_locked"
+	SecurityEventPasswordReset     SecurityEventType = "password_reset"
+	SecurityEventPasswordChanged   SecurityEventType = "password_changed"
+	SecurityEventMFAEnabled        SecurityEventType = "mfa_enabled"
+	SecurityEventMFADisabled       SecurityEventType = "mfa_disabled"
+	SecurityEventRecoveryCodeUsed  SecurityEventType = "mfa_recovery_code_used"
+)
@@ -0,0 +1,8 @@
+package enums
+
+type SigningKeyStatus string
+
+const (
+	SigningKeyActive  SigningKeyStatus = "active"
+	SigningKeyRetired SigningKeyStatus = "retired"
+)
@@ -0,0 +1,19 @@
+package enums
+
+// TenantRole is a user's role within their tenant, as opposed to the
+// permissions they hold on zones.
+type TenantRole string
+
+const (
+	TenantRoleAdmin  TenantRole = "tenant_admin"
+	TenantRoleMember TenantRole = "member"
+)
+
+func (r TenantRole) IsValid() bool {
+	switch r {
+	case TenantRoleAdmin, TenantRoleMember:
+		return true
+	default:
+		return false
+	}
+}
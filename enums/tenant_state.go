@@ -0,0 +1,14 @@
+package enums
+
+// TenantState is the in-memory lifecycle state of a tenant's connection pool,
+// as opposed to TenantStatus which is persisted in the master DB.
+type TenantState string
+
+const (
+	TenantStateProvisioning TenantState = "provisioning"
+	TenantStateReady        TenantState = "ready"
+	TenantStateFailed       TenantState = "failed"
+	TenantStateSuspended    TenantState = "suspended"
+	TenantStateDraining     TenantState = "draining"
+	TenantStateRemoved      TenantState = "removed"
+)
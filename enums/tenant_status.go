@@ -3,13 +3,15 @@ package enums
 type TenantStatus string
 
 const (
-	TenantStatusActive   TenantStatus = "active"
-	TenantStatusInactive TenantStatus = "inactive"
+	TenantStatusActive       TenantStatus = "active"
+	TenantStatusInactive     TenantStatus = "inactive"
+	TenantStatusProvisioning TenantStatus = "provisioning"
+	TenantStatusFailed       TenantStatus = "failed"
 )
 
 func (t TenantStatus) IsValid() bool {
 	switch t {
-	case TenantStatusActive, TenantStatusInactive:
+	case TenantStatusActive, TenantStatusInactive, TenantStatusProvisioning, TenantStatusFailed:
 		return true
 	default:
 		return false
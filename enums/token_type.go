@@ -3,13 +3,15 @@ package enums
 type TokenType string
 
 const (
-	TokenTypeAccess  TokenType = "access"
-	TokenTypeRefresh TokenType = "refresh"
+	TokenTypeAccess          TokenType = "access"
+	TokenTypeRefresh         TokenType = "refresh"
+	TokenTypeOperatorAccess  TokenType = "operator_access"
+	TokenTypeOperatorRefresh TokenType = "operator_refresh"
 )
 
 func (t TokenType) IsValid() bool {
 	switch t {
-	case TokenTypeAccess, TokenTypeRefresh:
+	case TokenTypeAccess, TokenTypeRefresh, TokenTypeOperatorAccess, TokenTypeOperatorRefresh:
 		return true
 	default:
 		return false
@@ -1,5 +1,7 @@
 package enums
 
+// UserPermission is the name of the zone role a user holds on a zone: one of
+// the built-in roles below or a role defined by the tenant.
 type UserPermission string
 
 const (
@@ -8,6 +10,7 @@ const (
 	UserViewer UserPermission = "viewer"
 )
 
+// IsValidUserPermission reports whether permission is a built-in role.
 func IsValidUserPermission(permission string) bool {
 	switch permission {
 	case "owner":
@@ -0,0 +1,33 @@
+package enums
+
+// ZoneAction is something a zone role allows on a zone and its subtree.
+type ZoneAction string
+
+const (
+	ZoneActionRead          ZoneAction = "zone.read"
+	ZoneActionUpdate        ZoneAction = "zone.update"
+	ZoneActionDelete        ZoneAction = "zone.delete"
+	ZoneActionCreateChild   ZoneAction = "zone.create_child"
+	ZoneActionMove          ZoneAction = "zone.move"
+	ZoneActionShareManage   ZoneAction = "share.manage"
+	ZoneActionMetadataWrite ZoneAction = "metadata.write"
+)
+
+var ZoneActions = []ZoneAction{
+	ZoneActionRead,
+	ZoneActionUpdate,
+	ZoneActionDelete,
+	ZoneActionCreateChild,
+	ZoneActionMove,
+	ZoneActionShareManage,
+	ZoneActionMetadataWrite,
+}
+
+func (a ZoneAction) IsValid() bool {
+	for _, action := range ZoneActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
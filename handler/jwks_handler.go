@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"golang-rest-user/provider/serviceProvider"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /.well-known/jwks.json
+// Served as a plain JWK set, not in the response envelope, so standard JWT
+// libraries can consume it.
+func JWKS(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, appService.JWTManager.JWKS())
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/response"
+	"golang-rest-user/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// POST /platform/auth/login
+func OperatorLogin(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	var req dto.OperatorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	tokens, err := appService.OperatorService.Login(req)
+	if err != nil {
+		response.Error(c, response.CodeUnauthorized, err.Error(), nil, http.StatusUnauthorized)
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// POST /platform/auth/refresh
+func OperatorRefresh(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	tokens, err := appService.OperatorService.Refresh(req.RefreshToken)
+	if err != nil {
+		response.Error(c, response.CodeUnauthorized, err.Error(), nil, http.StatusUnauthorized)
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// POST /platform/auth/logout
+func OperatorLogout(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	var req dto.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	if err := appService.OperatorService.Logout(req.RefreshToken); err != nil {
+		response.Error(c, response.CodeUnauthorized, err.Error(), nil, http.StatusUnauthorized)
+		return
+	}
+	response.Success(c, gin.H{"message": "logged out"})
+}
+
+// GET /platform/operators?page=1&pageSize=10&search=...
+func ListOperators(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	page, pageSize := utils.GetPageAndPageSize(c)
+	search := c.Query("search")
+
+	operators, total, err := appService.OperatorService.List(page, pageSize, search)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{
+		"data":      operators,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// POST /platform/operators
+func CreateOperator(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	var req dto.CreateOperatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	operator, err := appService.OperatorService.Create(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "exists") {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	response.Success(c, operator)
+}
+
+// PUT /platform/operators/:uuid
+func UpdateOperator(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	var req dto.UpdateOperatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	operator, err := appService.OperatorService.Update(c.Param("uuid"), req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "operator not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	response.Success(c, operator)
+}
+
+// DELETE /platform/operators/:uuid
+func DeleteOperator(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	if err := appService.OperatorService.Delete(c.Param("uuid"), c.GetUint("operator_id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "operator not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	response.Success(c, gin.H{"deleted": true})
+}
@@ -1,22 +1,25 @@
 package tenant
 
 import (
-	"golang-rest-user/provider/tenantProvider"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"golang-rest-user/dto"
 	"golang-rest-user/response"
+	"golang-rest-user/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 // POST /auth/register
 func Register(c *gin.Context) {
-	tenantCode := c.GetString("TENANT_CODE")
+	tenantCode := c.GetString("tenant_code")
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	var req dto.CreateUserRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -35,18 +38,25 @@ func Register(c *gin.Context) {
 
 // POST /auth/login
 func Login(c *gin.Context) {
-	tenantCode := c.GetString("TENANT_CODE")
+	tenantCode := c.GetString("tenant_code")
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	tenant := tenantInfo(c)
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
 
-	tokens, err := service.AuthService.Login(tenantCode, req)
+	tokens, err := tenant.AuthService.Login(tenantCode, req, clientInfo(c))
+	if loginThrottled(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		response.Error(c, response.CodeEmailNotVerified, err.Error(), nil, http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusUnauthorized)
 		return
@@ -55,20 +65,41 @@ func Login(c *gin.Context) {
 	response.Success(c, tokens)
 }
 
+// loginThrottled answers 423 for a locked account or 429 for a login that
+// must wait, with a Retry-After header, or 503 when the login guard cannot
+// tell, and reports whether it did.
+func loginThrottled(c *gin.Context, err error) bool {
+	if errors.Is(err, service.ErrLoginUnavailable) {
+		response.Error(c, response.CodeLoginUnavailable, err.Error(), nil, http.StatusServiceUnavailable)
+		return true
+	}
+	var throttled *service.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	if throttled.Locked {
+		response.Error(c, response.CodeAccountLocked, err.Error(), nil, http.StatusLocked)
+		return true
+	}
+	response.Error(c, response.CodeTooManyAttempts, err.Error(), nil, http.StatusTooManyRequests)
+	return true
+}
+
 // POST /auth/refresh
 func Refresh(c *gin.Context) {
-	tenantCode := c.GetString("TENANT_CODE")
+	tenantCode := c.GetString("tenant_code")
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	var req dto.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
 
-	tokens, err := service.AuthService.Refresh(tenantCode, req.RefreshToken)
+	tokens, err := service.AuthService.Refresh(tenantCode, req.RefreshToken, clientInfo(c))
 	if err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusUnauthorized)
 		return
@@ -76,22 +107,164 @@ func Refresh(c *gin.Context) {
 	response.Success(c, tokens)
 }
 
-// POST /auth/logout
+// POST /auth/logout?all=true
 func Logout(c *gin.Context) {
-	tenantCode := c.GetString("TENANT_CODE")
+	tenantCode := c.GetString("tenant_code")
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	var req dto.LogoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
 
-	if err := service.AuthService.Logout(tenantCode, req.RefreshToken); err != nil {
+	all := req.All || c.Query("all") == "true"
+	if err := service.AuthService.Logout(tenantCode, req.RefreshToken, all); err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusUnauthorized)
 		return
 	}
 	response.Success(c, gin.H{"message": "logged out"})
 }
+
+// POST /auth/password/forgot
+func ForgotPassword(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	if err := tenant.PasswordReset.Forgot(req.Username); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	// same answer whether the user exists or not
+	response.Success(c, gin.H{"message": "if the account exists, a reset mail has been sent"})
+}
+
+// POST /auth/password/reset
+func ResetPassword(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	if err := tenant.PasswordReset.Reset(req.Token, req.Password); err != nil {
+		if errors.Is(err, service.ErrPasswordResetInvalid) {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{"message": "password has been reset"})
+}
+
+// POST /auth/verify-email
+func VerifyEmail(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	if err := tenant.Verification.Verify(req.Token); err != nil {
+		if errors.Is(err, service.ErrEmailVerifyInvalid) {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{"message": "email verified"})
+}
+
+// POST /auth/verify-email/resend
+func ResendVerification(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	if err := tenant.Verification.Resend(req.Username); err != nil {
+		if errors.Is(err, service.ErrTooManyRequests) {
+			response.Error(c, response.CodeTooManyAttempts, err.Error(), nil, http.StatusTooManyRequests)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	// same answer whether the user exists or is already verified
+	response.Success(c, gin.H{"message": "if the account needs verification, a mail has been sent"})
+}
+
+// POST /auth/mfa/verify
+func VerifyMFA(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := tenant.AuthService.VerifyMFA(tenantCode, req, clientInfo(c))
+	if loginThrottled(c, err) {
+		return
+	}
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// POST /auth/mfa/enroll
+// For tenants that require MFA: starts enrollment for a login that returned
+// mfa_enrollment_required, the code is then sent to /auth/mfa/verify.
+func EnrollMFAForLogin(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	enrollment, err := tenant.AuthService.EnrollMFA(tenantCode, req.MFAToken)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, enrollment)
+}
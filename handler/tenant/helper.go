@@ -0,0 +1,21 @@
+package tenant
+
+import (
+	"golang-rest-user/dto"
+	"golang-rest-user/provider/tenantProvider"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tenantInfo returns the tenant acquired by the tenant or auth middleware.
+func tenantInfo(c *gin.Context) *tenantProvider.TenantInfo {
+	info, _ := c.MustGet("tenant_info").(*tenantProvider.TenantInfo)
+	return info
+}
+
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
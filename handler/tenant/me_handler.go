@@ -0,0 +1,79 @@
+package tenant
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-rest-user/dto"
+	"golang-rest-user/response"
+	"golang-rest-user/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /me
+func GetMe(c *gin.Context) {
+	tenant := tenantInfo(c)
+
+	user, err := tenant.UserService.GetByID(c.GetUint("user_id"))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "user not found", nil, http.StatusNotFound)
+		return
+	}
+	response.Success(c, user)
+}
+
+// PUT /me
+func UpdateMe(c *gin.Context) {
+	tenant := tenantInfo(c)
+	var req dto.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	user, err := tenant.UserService.UpdateByID(c.GetUint("user_id"), req)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	response.Success(c, user)
+}
+
+// PUT /me/password
+// Every other session of the user is ended, the current one stays. Wrong
+// current passwords are throttled like failed logins.
+func ChangeMyPassword(c *gin.Context) {
+	tenant := tenantInfo(c)
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	err := tenant.UserService.ChangePassword(c.GetUint("user_id"), c.GetString("session_id"), clientInfo(c), req)
+	if loginThrottled(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrWrongPassword) {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{"message": "password changed"})
+}
+
+// GET /me/zones
+func GetMyZones(c *gin.Context) {
+	tenant := tenantInfo(c)
+
+	summary, err := tenant.ZoneService.GetUserZonesSummary(c.GetUint("user_id"))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, summary)
+}
@@ -0,0 +1,111 @@
+package tenant
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-rest-user/dto"
+	"golang-rest-user/response"
+	"golang-rest-user/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /mfa
+func GetMFAStatus(c *gin.Context) {
+	tenant := tenantInfo(c)
+
+	status, err := tenant.MFAService.Status(c.GetUint("user_id"))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// POST /mfa/enroll
+func EnrollMFA(c *gin.Context) {
+	tenant := tenantInfo(c)
+
+	enrollment, err := tenant.MFAService.Enroll(c.GetUint("user_id"))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, enrollment)
+}
+
+// POST /mfa/confirm
+func ConfirmMFA(c *gin.Context) {
+	tenant := tenantInfo(c)
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := tenant.MFAService.Confirm(c.GetUint("user_id"), clientInfo(c), req.Code)
+	if loginThrottled(c, err) {
+		return
+	}
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, codes)
+}
+
+// POST /mfa/disable
+func DisableMFA(c *gin.Context) {
+	tenant := tenantInfo(c)
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	err := tenant.MFAService.Disable(c.GetUint("user_id"), clientInfo(c), req.Code)
+	if loginThrottled(c, err) {
+		return
+	}
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "mfa disabled"})
+}
+
+// POST /mfa/recovery-codes
+func RegenerateRecoveryCodes(c *gin.Context) {
+	tenant := tenantInfo(c)
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := tenant.MFAService.RegenerateRecoveryCodes(c.GetUint("user_id"), clientInfo(c), req.Code)
+	if loginThrottled(c, err) {
+		return
+	}
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	response.Success(c, codes)
+}
+
+func mfaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFAInvalidCode), errors.Is(err, service.ErrMFAChallengeInvalid):
+		response.Error(c, response.CodeUnauthorized, err.Error(), nil, http.StatusUnauthorized)
+	case errors.Is(err, service.ErrMFARequired):
+		response.Error(c, response.CodeForbidden, err.Error(), nil, http.StatusForbidden)
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+	case errors.Is(err, service.ErrMFANotEnabled), errors.Is(err, service.ErrMFANotEnrolled):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+	default:
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+	}
+}
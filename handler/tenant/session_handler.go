@@ -0,0 +1,59 @@
+package tenant
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-rest-user/response"
+	"golang-rest-user/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /sessions
+func ListSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	tenant := tenantInfo(c)
+
+	sessions, err := tenant.SessionService.List(userID, c.GetString("session_id"))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, sessions)
+}
+
+// DELETE /sessions/:id
+func RevokeSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	tenant := tenantInfo(c)
+
+	if err := tenant.SessionService.Revoke(userID, c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{"message": "session revoked"})
+}
+
+// DELETE /sessions
+// Revokes every session except the one making the request.
+func RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	tenant := tenantInfo(c)
+
+	currentSessionID := c.GetString("session_id")
+	if currentSessionID == "" {
+		response.Error(c, response.CodeBadRequest, "token is not bound to a session, log in again", nil, http.StatusBadRequest)
+		return
+	}
+	revoked, err := tenant.SessionService.RevokeOthers(userID, currentSessionID)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{"revoked": revoked})
+}
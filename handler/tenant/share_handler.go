@@ -2,7 +2,6 @@ package tenant
 
 import (
 	"golang-rest-user/dto"
-	"golang-rest-user/provider/tenantProvider"
 	"golang-rest-user/response"
 	"net/http"
 
@@ -17,10 +16,10 @@ func GetSharedUsers(c *gin.Context) {
 	}
 	userID := c.GetUint("user_id")
 	zoneUUID := c.Param("uuid")
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	userResponse, err := service.ShareService.GetSharedUser(zoneUUID, userID)
 	if err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, userResponse)
@@ -40,10 +39,10 @@ func ShareZone(c *gin.Context) {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
-	shareResponse, err := service.ShareService.ShareZone(userID, zoneUUID, req)
+	tenant := tenantInfo(c)
+	shareResponse, err := tenant.ShareService.ShareZone(userID, zoneUUID, req)
 	if err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, shareResponse)
@@ -58,14 +57,14 @@ func UpdatePermission(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	zoneUUID := c.Param("uuid")
 	userUUID := c.Param("user_uuid")
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	tenant := tenantInfo(c)
 	var req = dto.ShareDTORequest{}
 	if err := c.ShouldBind(&req); err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
-	if err := service.ShareService.UpdatePermission(zoneUUID, userUUID, userID, req); err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+	if err := tenant.ShareService.UpdatePermission(zoneUUID, userUUID, userID, req); err != nil {
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, nil)
@@ -80,10 +79,10 @@ func RevokeZone(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	zoneUUID := c.Param("uuid")
 	userUUID := c.Param("user_uuid")
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	total, err := service.ShareService.RevokeUser(zoneUUID, userUUID, userID)
 	if err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, gin.H{"deleted": total})
@@ -1,9 +1,11 @@
 package tenant
 
 import (
+	"errors"
 	"golang-rest-user/dto"
-	"golang-rest-user/provider/tenantProvider"
+	"golang-rest-user/enums"
 	"golang-rest-user/response"
+	"golang-rest-user/service"
 	"golang-rest-user/utils"
 	"net/http"
 	"strings"
@@ -17,7 +19,7 @@ func ListUsers(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 
 	page, pageSize := utils.GetPageAndPageSize(c)
 	search := c.Query("search")
@@ -42,7 +44,7 @@ func CreateUser(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	tenant := tenantInfo(c)
 
 	var req dto.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -50,8 +52,12 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
-	userResponse, err := service.UserService.Create(req)
+	userResponse, err := tenant.UserService.Create(req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidRole) {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+			return
+		}
 		if strings.Contains(err.Error(), "exists") {
 			response.Error(c, response.CodeBadRequest, "username already exists", nil, http.StatusConflict)
 			return
@@ -71,7 +77,7 @@ func GetByUserUUID(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 
 	uuid := c.Param("uuid")
 	userResponse, err := service.UserService.GetByUUID(uuid)
@@ -88,7 +94,7 @@ func UpdateUser(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	uuid := c.Param("uuid")
 
 	var req dto.UpdateUserRequest
@@ -111,7 +117,7 @@ func DeleteManyUsers(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	uuidsParam := c.Query("uuids")
 	if uuidsParam == "" {
 		response.Error(c, response.CodeBadRequest, "ids query param required", nil, http.StatusBadRequest)
@@ -132,3 +138,62 @@ func DeleteManyUsers(c *gin.Context) {
 	}
 	response.Success(c, gin.H{"deleted": deleted})
 }
+
+// PUT /users/:uuid/role
+func UpdateUserRole(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+
+	var req dto.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+
+	userResponse, err := tenant.UserService.SetRole(c.Param("uuid"), enums.TenantRole(req.Role))
+	switch {
+	case errors.Is(err, service.ErrInvalidRole):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+	case errors.Is(err, service.ErrLastAdmin):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+	case err != nil:
+		response.Error(c, response.CodeBadRequest, "user not found", nil, http.StatusNotFound)
+	default:
+		response.Success(c, userResponse)
+	}
+}
+
+// POST /users/:uuid/unlock
+func UnlockUser(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	service := tenantInfo(c)
+
+	userResponse, err := service.UserService.Unlock(c.Param("uuid"))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "user not found", nil, http.StatusNotFound)
+		return
+	}
+	response.Success(c, userResponse)
+}
+
+// DELETE /users/:uuid/mfa
+// For users who lost both their authenticator and their recovery codes.
+func ResetUserMFA(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	service := tenantInfo(c)
+
+	if err := service.MFAService.Reset(c.Param("uuid")); err != nil {
+		response.Error(c, response.CodeBadRequest, "user not found", nil, http.StatusNotFound)
+		return
+	}
+	response.Success(c, gin.H{"message": "mfa reset"})
+}
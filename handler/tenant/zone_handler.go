@@ -1,10 +1,15 @@
 package tenant
 
 import (
+	"errors"
 	"golang-rest-user/dto"
-	"golang-rest-user/provider/tenantProvider"
 	"golang-rest-user/response"
+	"golang-rest-user/service"
+	"golang-rest-user/utils"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,7 +21,7 @@ func CreateZone(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 
 	var req = dto.ZoneDTORequest{}
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -26,28 +31,97 @@ func CreateZone(c *gin.Context) {
 
 	zoneResponse, err := service.ZoneService.CreateZone(&req, userId)
 	if err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, zoneResponse)
 }
 
-// GET /zones
+// GET /zones?page=1&pageSize=10
 func ListZones(c *gin.Context) {
 	tenantCode := c.GetString("tenant_code")
 	userId := c.GetUint("user_id")
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
-	zoneResponse, err := service.ZoneService.GetUserZones(userId)
+	service := tenantInfo(c)
+	page, pageSize := utils.GetPageAndPageSize(c)
+	zoneResponse, total, err := service.ZoneService.GetUserZones(userId, page, pageSize)
 	if err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
+	response.Success(c, gin.H{
+		"data":      zoneResponse,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// GET /zones/:uuid?breadcrumb=true
+func GetZone(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	zoneResponse, err := service.ZoneService.GetZone(c.Param("uuid"), userID, c.Query("breadcrumb") == "true")
+	if err != nil {
+		zoneError(c, err)
+		return
+	}
 	response.Success(c, zoneResponse)
 }
 
+// GET /zones/:uuid/ancestors
+func GetZoneAncestors(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	ancestors, err := service.ZoneService.GetAncestors(c.Param("uuid"), userID)
+	if err != nil {
+		zoneError(c, err)
+		return
+	}
+	response.Success(c, ancestors)
+}
+
+// GET /zones/:uuid/tree?depth=3&include_metadata=true&fields=name,type&lazy=true
+func GetZoneTree(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	tenant := tenantInfo(c)
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(service.DefaultZoneTreeDepth)))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "depth must be a number", nil, http.StatusBadRequest)
+		return
+	}
+	opts := dto.ZoneTreeOptions{
+		Depth:           depth,
+		IncludeMetadata: c.Query("include_metadata") == "true",
+		Lazy:            c.Query("lazy") == "true",
+	}
+	if fields := c.Query("fields"); fields != "" {
+		opts.Fields = strings.Split(fields, ",")
+	}
+
+	tree, err := tenant.ZoneService.GetZoneTree(c.Param("uuid"), userID, opts)
+	if err != nil {
+		zoneError(c, err)
+		return
+	}
+	response.Success(c, tree)
+}
+
 // GET /zones/share-with-me
 func ListSharedZones(c *gin.Context) {
 	tenantCode := c.GetString("tenant_code")
@@ -55,7 +129,7 @@ func ListSharedZones(c *gin.Context) {
 		return
 	}
 	userID := c.GetUint("user_id")
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	service := tenantInfo(c)
 	zoneResponses, err := service.ZoneService.GetSharedZone(userID)
 	if err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
@@ -71,20 +145,42 @@ func UpdateZone(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
 	var req = dto.ZoneDTORequest{}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
 		return
 	}
-	zoneResponse, err := service.ZoneService.UpdateZone(&req, uuid)
+	zoneResponse, err := service.ZoneService.UpdateZone(&req, uuid, userID)
 	if err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, zoneResponse)
 }
 
+// POST /zones/:uuid/move
+func MoveZone(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	var req dto.MoveZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	moved, err := service.ZoneService.MoveZone(c.Param("uuid"), req.ParentID, userID)
+	if err != nil {
+		zoneError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"moved": moved})
+}
+
 // DELETE /zones/:uuid
 func DeleteZone(c *gin.Context) {
 	tenantCode := c.GetString("tenant_code")
@@ -92,11 +188,85 @@ func DeleteZone(c *gin.Context) {
 	if tenantCode == "" {
 		return
 	}
-	service := tenantProvider.GetTenantInfo(tenantCode)
-	deleted, err := service.ZoneService.DeleteZones(uuid)
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	deleted, err := service.ZoneService.DeleteZones(uuid, userID)
 	if err != nil {
-		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		zoneError(c, err)
 		return
 	}
 	response.Success(c, gin.H{"zone deleted": deleted})
 }
+
+// GET /zones/trash?page=1&pageSize=10
+func ListZoneTrash(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	page, pageSize := utils.GetPageAndPageSize(c)
+	trashed, total, err := service.ZoneService.ListTrash(userID, page, pageSize)
+	if err != nil {
+		log.Printf("list zone trash: %v", err)
+		response.Error(c, response.CodeInternalError, "could not load the trash", nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{
+		"data":      trashed,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// POST /zones/trash/:uuid/restore
+func RestoreZone(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	restored, err := service.ZoneService.RestoreZone(c.Param("uuid"), userID)
+	if err != nil {
+		zoneError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"restored": restored})
+}
+
+// DELETE /zones/trash/:uuid
+func PurgeZone(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	userID := c.GetUint("user_id")
+	service := tenantInfo(c)
+	purged, err := service.ZoneService.PurgeZone(c.Param("uuid"), userID)
+	if err != nil {
+		zoneError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"purged": purged})
+}
+
+// zoneError answers a zone or share service error. Denials and unknown zones
+// get their own codes so clients can tell them from bad input.
+func zoneError(c *gin.Context, err error) {
+	var invalid *service.ZoneValidationError
+	switch {
+	case errors.As(err, &invalid):
+		response.Error(c, response.CodeZoneInvalid, err.Error(), invalid.Errors, http.StatusBadRequest)
+	case errors.Is(err, service.ErrPermissionDenied):
+		response.Error(c, response.CodeZoneForbidden, err.Error(), nil, http.StatusForbidden)
+	case errors.Is(err, service.ErrZoneNotFound):
+		response.Error(c, response.CodeZoneNotFound, err.Error(), nil, http.StatusNotFound)
+	case errors.Is(err, service.ErrZoneParentMissing), errors.Is(err, service.ErrShareInherited), errors.Is(err, service.ErrShareNarrowsSubzone):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+	default:
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,95 @@
+package tenant
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/response"
+	"golang-rest-user/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /zone-roles
+func ListZoneRoles(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	roles, err := tenant.ZoneRoles.List()
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, roles)
+}
+
+// POST /zone-roles
+func CreateZoneRole(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.CreateZoneRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	role, err := tenant.ZoneRoles.Create(req)
+	if err != nil {
+		zoneRoleError(c, err)
+		return
+	}
+	response.Success(c, role)
+}
+
+// PUT /zone-roles/:name
+func UpdateZoneRole(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.UpdateZoneRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	role, err := tenant.ZoneRoles.Update(c.Param("name"), req)
+	if err != nil {
+		zoneRoleError(c, err)
+		return
+	}
+	response.Success(c, role)
+}
+
+// DELETE /zone-roles/:name
+func DeleteZoneRole(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	if err := tenant.ZoneRoles.Delete(c.Param("name")); err != nil {
+		zoneRoleError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+func zoneRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidZoneRole), errors.Is(err, service.ErrInvalidZoneAction):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+	case errors.Is(err, service.ErrZoneRoleNotFound):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusNotFound)
+	case errors.Is(err, service.ErrZoneRoleExists), errors.Is(err, service.ErrZoneRoleInUse):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+	case errors.Is(err, service.ErrZoneRoleBuiltIn):
+		response.Error(c, response.CodeForbidden, err.Error(), nil, http.StatusForbidden)
+	default:
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+	}
+}
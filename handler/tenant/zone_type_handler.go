@@ -0,0 +1,125 @@
+package tenant
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/response"
+	"golang-rest-user/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /zone-types
+func ListZoneTypes(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	types, err := tenant.ZoneTypes.List()
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, types)
+}
+
+// GET /zone-types/enforcement
+// enforced is true once the tenant has registered a type: from then on every
+// zone created, updated, moved or restored needs a registered type.
+func GetZoneTypeEnforcement(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	registry, err := tenant.ZoneTypes.Registry()
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, gin.H{"enforced": registry.Enforced()})
+}
+
+// GET /zone-types/:name
+func GetZoneType(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	zoneType, err := tenant.ZoneTypes.Get(c.Param("name"))
+	if err != nil {
+		zoneTypeError(c, err)
+		return
+	}
+	response.Success(c, zoneType)
+}
+
+// POST /zone-types
+func CreateZoneType(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.CreateZoneTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	zoneType, err := tenant.ZoneTypes.Create(req)
+	if err != nil {
+		zoneTypeError(c, err)
+		return
+	}
+	response.Success(c, zoneType)
+}
+
+// PUT /zone-types/:name
+func UpdateZoneType(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	var req dto.UpdateZoneTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	zoneType, err := tenant.ZoneTypes.Update(c.Param("name"), req)
+	if err != nil {
+		zoneTypeError(c, err)
+		return
+	}
+	response.Success(c, zoneType)
+}
+
+// DELETE /zone-types/:name
+func DeleteZoneType(c *gin.Context) {
+	tenantCode := c.GetString("tenant_code")
+	if tenantCode == "" {
+		return
+	}
+	tenant := tenantInfo(c)
+	if err := tenant.ZoneTypes.Delete(c.Param("name")); err != nil {
+		zoneTypeError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+func zoneTypeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidZoneSchema), errors.Is(err, service.ErrInvalidZoneType):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+	case errors.Is(err, service.ErrZoneTypeNotFound):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusNotFound)
+	case errors.Is(err, service.ErrZoneTypeExists), errors.Is(err, service.ErrZoneTypeInUse):
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+	default:
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+	}
+}
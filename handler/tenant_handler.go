@@ -3,8 +3,11 @@ package handler
 import (
 	"errors"
 	"golang-rest-user/dto"
+	"golang-rest-user/models"
 	"golang-rest-user/provider/serviceProvider"
+	"golang-rest-user/provider/tenantProvider"
 	"golang-rest-user/response"
+	"golang-rest-user/service"
 	"golang-rest-user/utils"
 	"net/http"
 	"strings"
@@ -42,7 +45,7 @@ func CreateTenant(c *gin.Context) {
 		return
 	}
 
-	tenantResponse, err := appService.TenantService.Create(req)
+	createResponse, err := appService.TenantService.Create(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "exists") {
 			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
@@ -56,9 +59,8 @@ func CreateTenant(c *gin.Context) {
 		return
 	}
 
-	//location := c.Request.URL.Path + "/" + strconv.Itoa(int(tenantResponse.ID))
-	//c.Header("Location", location)
-	response.Success(c, tenantResponse)
+	c.Header("Location", "/api/v1/tenants/provisioning/"+createResponse.ProvisioningID)
+	response.Accepted(c, createResponse)
 }
 
 // GET /tenants/:code
@@ -95,6 +97,10 @@ func UpdateTenant(c *gin.Context) {
 
 	tenantResponse, err := appService.TenantService.Update(code, req)
 	if err != nil {
+		if errors.Is(err, service.ErrTenantProvisioning) {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+			return
+		}
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusNotFound)
 		return
 	}
@@ -110,8 +116,126 @@ func DeleteTenant(c *gin.Context) {
 		response.Error(c, response.CodeBadRequest, "tenant code is required", nil, http.StatusBadRequest)
 	}
 	if err := appService.TenantService.Delete(code); err != nil {
+		if errors.Is(err, service.ErrTenantProvisioning) {
+			response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+			return
+		}
 		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusNotFound)
 		return
 	}
 	response.Success(c, gin.H{"deleted": true})
 }
+
+// POST /tenants/:code/suspend
+func SuspendTenant(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	tenantResponse, err := appService.TenantService.Suspend(c.Param("code"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "tenant not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+		return
+	}
+	response.Success(c, tenantResponse)
+}
+
+// POST /tenants/:code/resume
+func ResumeTenant(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	tenantResponse, err := appService.TenantService.Resume(c.Param("code"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "tenant not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+		return
+	}
+	response.Success(c, tenantResponse)
+}
+
+// GET /tenants/:code/state
+func GetTenantState(c *gin.Context) {
+	state, err := tenantProvider.GetTenantState(c.Param("code"))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusNotFound)
+		return
+	}
+	response.Success(c, state)
+}
+
+// GET /tenants/schema-versions
+func GetSchemaVersions(c *gin.Context) {
+	master, err := tenantProvider.MasterSchemaVersion(c.Request.Context())
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, dto.SchemaVersionsResponse{
+		Master:  master,
+		Tenants: tenantProvider.SchemaVersions(c.Request.Context(), 8),
+	})
+}
+
+// GET /tenants/provisioning/:uuid
+func GetProvisioning(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	provisioning, err := appService.ProvisioningService.Get(c.Param("uuid"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "provisioning not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusInternalServerError)
+		return
+	}
+	response.Success(c, provisioning)
+}
+
+// POST /tenants/provisioning/:uuid/retry
+func RetryProvisioning(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	provisioning, err := appService.ProvisioningService.Retry(c.Param("uuid"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "provisioning not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusConflict)
+		return
+	}
+	response.Accepted(c, provisioning)
+}
+
+// GET /tenants/:code/settings
+func GetTenantSettings(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	settings, err := appService.TenantService.GetSettings(c.Param("code"))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "tenant not found", nil, http.StatusNotFound)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// PUT /tenants/:code/settings
+func UpdateTenantSettings(c *gin.Context) {
+	appService := serviceProvider.GetInstance()
+	var req models.TenantSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	settings, err := appService.TenantService.UpdateSettings(c.Param("code"), req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.CodeBadRequest, "tenant not found", nil, http.StatusNotFound)
+			return
+		}
+		response.Error(c, response.CodeBadRequest, err.Error(), nil, http.StatusBadRequest)
+		return
+	}
+	response.Success(c, settings)
+}
@@ -0,0 +1,467 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// Schema is a compiled JSON Schema. It supports the keywords zone metadata
+// needs: type, enum, const, properties, required, additionalProperties,
+// items, minItems, maxItems, uniqueItems, minLength, maxLength, pattern,
+// format, minimum, maximum, exclusiveMinimum, exclusiveMaximum and
+// multipleOf, plus annotations such as title and description. Compile rejects
+// any other keyword, and formats it cannot check, rather than let a schema
+// promise checks that never run.
+//
+// pattern is a Go regular expression (RE2 syntax), not ECMA-262: lookaround
+// and backreferences do not compile, and \d, \w and \s match ASCII only. As in
+// JSON Schema it matches anywhere in the string unless anchored.
+type Schema struct {
+	// reject is the false schema, which nothing satisfies.
+	reject bool
+
+	types    []string
+	enum     []interface{}
+	constVal interface{}
+	hasConst bool
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+}
+
+// Error is a value that does not satisfy the schema. Path locates it, e.g.
+// "address.zip" or "tags[2]", and is empty for the document itself.
+type Error struct {
+	Path    string `json:"field"`
+	Message string `json:"message"`
+}
+
+var keywords = []string{
+	"type", "enum", "const",
+	"properties", "required", "additionalProperties",
+	"items", "minItems", "maxItems", "uniqueItems",
+	"minLength", "maxLength", "pattern", "format",
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+}
+
+// annotations are accepted and have no effect on validation.
+var annotations = []string{
+	"$schema", "$id", "$comment", "title", "description",
+	"default", "examples", "deprecated", "readOnly", "writeOnly",
+}
+
+var knownTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// Compile parses a schema document and checks that the supported keywords
+// are well-formed.
+func Compile(raw []byte) (*Schema, error) {
+	doc, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return compile(doc, "")
+}
+
+func compile(doc interface{}, at string) (*Schema, error) {
+	switch v := doc.(type) {
+	case bool:
+		return &Schema{reject: !v}, nil
+	case map[string]interface{}:
+		return compileObject(v, at)
+	}
+	return nil, schemaError(at, "a schema must be an object or a boolean")
+}
+
+func compileObject(doc map[string]interface{}, at string) (*Schema, error) {
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !contains(keywords, name) && !contains(annotations, name) {
+			return nil, schemaError(at+"/"+name, "unsupported keyword")
+		}
+	}
+
+	s := &Schema{}
+	var err error
+
+	if t, ok := doc["type"]; ok {
+		if s.types, err = stringList(t); err != nil {
+			return nil, schemaError(at+"/type", "must be a type name or a list of them")
+		}
+		for _, name := range s.types {
+			if !contains(knownTypes, name) {
+				return nil, schemaError(at+"/type", fmt.Sprintf("unknown type %q", name))
+			}
+		}
+	}
+	if e, ok := doc["enum"]; ok {
+		list, ok := e.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, schemaError(at+"/enum", "must be a non-empty array")
+		}
+		s.enum = list
+	}
+	if c, ok := doc["const"]; ok {
+		s.constVal, s.hasConst = c, true
+	}
+
+	if p, ok := doc["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return nil, schemaError(at+"/properties", "must be an object")
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, sub := range props {
+			if s.properties[name], err = compile(sub, at+"/properties/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r, ok := doc["required"]; ok {
+		if s.required, err = stringList(r); err != nil {
+			return nil, schemaError(at+"/required", "must be an array of property names")
+		}
+	}
+	if a, ok := doc["additionalProperties"]; ok {
+		if s.additionalProperties, err = compile(a, at+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+
+	if i, ok := doc["items"]; ok {
+		if s.items, err = compile(i, at+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	if s.minItems, err = count(doc, "minItems", at); err != nil {
+		return nil, err
+	}
+	if s.maxItems, err = count(doc, "maxItems", at); err != nil {
+		return nil, err
+	}
+	if u, ok := doc["uniqueItems"]; ok {
+		if s.uniqueItems, ok = u.(bool); !ok {
+			return nil, schemaError(at+"/uniqueItems", "must be a boolean")
+		}
+	}
+
+	if s.minLength, err = count(doc, "minLength", at); err != nil {
+		return nil, err
+	}
+	if s.maxLength, err = count(doc, "maxLength", at); err != nil {
+		return nil, err
+	}
+	if p, ok := doc["pattern"]; ok {
+		expr, ok := p.(string)
+		if !ok {
+			return nil, schemaError(at+"/pattern", "must be a string")
+		}
+		if s.pattern, err = regexp.Compile(expr); err != nil {
+			return nil, schemaError(at+"/pattern", err.Error())
+		}
+	}
+	if f, ok := doc["format"]; ok {
+		if s.format, ok = f.(string); !ok {
+			return nil, schemaError(at+"/format", "must be a string")
+		}
+		if _, ok := formats[s.format]; !ok {
+			return nil, schemaError(at+"/format", fmt.Sprintf("unsupported format, use one of %s", formatNames()))
+		}
+	}
+
+	for keyword, target := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	} {
+		v, ok := doc[keyword]
+		if !ok {
+			continue
+		}
+		n, ok := v.(float64)
+		if !ok {
+			return nil, schemaError(at+"/"+keyword, "must be a number")
+		}
+		*target = &n
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, schemaError(at+"/multipleOf", "must be greater than 0")
+	}
+	return s, nil
+}
+
+// Validate checks a JSON document against the schema and returns every
+// violation, ordered by path.
+func (s *Schema) Validate(raw []byte) ([]Error, error) {
+	doc, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	var errs []Error
+	s.validate(doc, "", &errs)
+	return errs, nil
+}
+
+func (s *Schema) validate(v interface{}, path string, errs *[]Error) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.reject {
+		fail("is not allowed")
+		return
+	}
+	if len(s.types) > 0 && !s.matchesType(v) {
+		fail("must be of type %s", strings.Join(s.types, " or "))
+		return
+	}
+	if s.enum != nil && !containsValue(s.enum, v) {
+		fail("must be one of %s", encode(s.enum))
+	}
+	if s.hasConst && !equal(s.constVal, v) {
+		fail("must be %s", encode(s.constVal))
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, path, errs)
+	case []interface{}:
+		s.validateArray(v, path, errs, fail)
+	case string:
+		s.validateString(v, fail)
+	case float64:
+		s.validateNumber(v, fail)
+	}
+}
+
+func (s *Schema) validateObject(v map[string]interface{}, path string, errs *[]Error) {
+	for _, name := range s.required {
+		if _, ok := v[name]; !ok {
+			*errs = append(*errs, Error{Path: join(path, name), Message: "is required"})
+		}
+	}
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if sub, ok := s.properties[name]; ok {
+			sub.validate(v[name], join(path, name), errs)
+		} else if s.additionalProperties != nil {
+			s.additionalProperties.validate(v[name], join(path, name), errs)
+		}
+	}
+}
+
+func (s *Schema) validateArray(v []interface{}, path string, errs *[]Error, fail func(string, ...interface{})) {
+	if s.minItems != nil && len(v) < *s.minItems {
+		fail("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(v) > *s.maxItems {
+		fail("must have at most %d items", *s.maxItems)
+	}
+	if s.uniqueItems {
+		for i := range v {
+			if containsValue(v[:i], v[i]) {
+				fail("must not contain duplicate items")
+				break
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range v {
+			s.items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+func (s *Schema) validateString(v string, fail func(string, ...interface{})) {
+	length := len([]rune(v))
+	if s.minLength != nil && length < *s.minLength {
+		fail("must be at least %d characters", *s.minLength)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		fail("must be at most %d characters", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		fail("must match %s", s.pattern)
+	}
+	if s.format != "" && !formats[s.format](v) {
+		fail("must be a valid %s", s.format)
+	}
+}
+
+func (s *Schema) validateNumber(v float64, fail func(string, ...interface{})) {
+	if s.minimum != nil && v < *s.minimum {
+		fail("must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil && v > *s.maximum {
+		fail("must be <= %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+		fail("must be > %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+		fail("must be < %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		q := v / *s.multipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+func (s *Schema) matchesType(v interface{}) bool {
+	for _, t := range s.types {
+		switch t {
+		case "object":
+			if _, ok := v.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := v.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := v.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := v.(float64); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case "null":
+			if v == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decode(raw []byte) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidJSON)
+	}
+	return doc, nil
+}
+
+func schemaError(at, msg string) error {
+	if at == "" {
+		at = "/"
+	}
+	return fmt.Errorf("invalid schema at %s: %s", at, msg)
+}
+
+func count(doc map[string]interface{}, keyword, at string) (*int, error) {
+	v, ok := doc[keyword]
+	if !ok {
+		return nil, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != math.Trunc(n) {
+		return nil, schemaError(at+"/"+keyword, "must be a non-negative integer")
+	}
+	i := int(n)
+	return &i, nil
+}
+
+func stringList(v interface{}) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("not a string list")
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, errors.New("not a string list")
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares decoded JSON values; encoding/json sorts map keys, so equal
+// values encode the same.
+func equal(a, b interface{}) bool {
+	return encode(a) == encode(b)
+}
+
+func encode(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,198 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{name: "true", schema: `true`},
+		{name: "false", schema: `false`},
+		{name: "empty", schema: `{}`},
+		{
+			name: "every keyword",
+			schema: `{
+				"type": "object", "enum": [{}], "const": {},
+				"properties": {"a": {"type": ["string", "null"]}}, "required": ["a"], "additionalProperties": false,
+				"items": true, "minItems": 0, "maxItems": 2, "uniqueItems": true,
+				"minLength": 1, "maxLength": 2, "pattern": "^a", "format": "email",
+				"minimum": 0, "maximum": 1, "exclusiveMinimum": 0, "exclusiveMaximum": 1.5, "multipleOf": 0.5
+			}`,
+		},
+		{
+			name: "annotations",
+			schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "x", "$comment": "c",
+				"title": "t", "description": "d", "default": 1, "examples": [1],
+				"deprecated": false, "readOnly": false, "writeOnly": false}`,
+		},
+		{name: "invalid JSON", schema: `{`, wantErr: "invalid JSON"},
+		{name: "trailing data", schema: `{} {}`, wantErr: "invalid JSON"},
+		{name: "not a schema", schema: `1`, wantErr: "at /: a schema must be an object or a boolean"},
+
+		{name: "unsupported keyword", schema: `{"oneOf": []}`, wantErr: "at /oneOf: unsupported keyword"},
+		{name: "unsupported nested keyword", schema: `{"properties": {"a": {"$ref": "#"}}}`, wantErr: "at /properties/a/$ref: unsupported keyword"},
+		{name: "unsupported keyword in items", schema: `{"items": {"contains": {}}}`, wantErr: "at /items/contains: unsupported keyword"},
+		{name: "misspelled keyword", schema: `{"maxLenght": 3}`, wantErr: "at /maxLenght: unsupported keyword"},
+
+		{name: "type not a string", schema: `{"type": 1}`, wantErr: "at /type: must be a type name"},
+		{name: "unknown type", schema: `{"type": ["string", "date"]}`, wantErr: `at /type: unknown type "date"`},
+		{name: "enum not an array", schema: `{"enum": "a"}`, wantErr: "at /enum: must be a non-empty array"},
+		{name: "empty enum", schema: `{"enum": []}`, wantErr: "at /enum: must be a non-empty array"},
+		{name: "properties not an object", schema: `{"properties": []}`, wantErr: "at /properties: must be an object"},
+		{name: "property not a schema", schema: `{"properties": {"a": "string"}}`, wantErr: "at /properties/a: a schema must be"},
+		{name: "required not names", schema: `{"required": [1]}`, wantErr: "at /required: must be an array of property names"},
+		{name: "additionalProperties not a schema", schema: `{"additionalProperties": 1}`, wantErr: "at /additionalProperties: a schema must be"},
+		{name: "items not a schema", schema: `{"items": [{}]}`, wantErr: "at /items: a schema must be"},
+		{name: "negative minItems", schema: `{"minItems": -1}`, wantErr: "at /minItems: must be a non-negative integer"},
+		{name: "fractional maxItems", schema: `{"maxItems": 1.5}`, wantErr: "at /maxItems: must be a non-negative integer"},
+		{name: "uniqueItems not a boolean", schema: `{"uniqueItems": 1}`, wantErr: "at /uniqueItems: must be a boolean"},
+		{name: "minLength not a number", schema: `{"minLength": "1"}`, wantErr: "at /minLength: must be a non-negative integer"},
+		{name: "negative maxLength", schema: `{"maxLength": -2}`, wantErr: "at /maxLength: must be a non-negative integer"},
+		{name: "pattern not a string", schema: `{"pattern": 1}`, wantErr: "at /pattern: must be a string"},
+		{name: "invalid pattern", schema: `{"pattern": "("}`, wantErr: "at /pattern: error parsing regexp"},
+		{name: "format not a string", schema: `{"format": true}`, wantErr: "at /format: must be a string"},
+		{name: "unsupported format", schema: `{"format": "ipv4"}`, wantErr: "at /format: unsupported format, use one of date, date-time, email, uri"},
+		{name: "ECMA-262 only pattern", schema: `{"pattern": "^(?!admin)"}`, wantErr: "at /pattern: error parsing regexp"},
+		{name: "minimum not a number", schema: `{"minimum": "0"}`, wantErr: "at /minimum: must be a number"},
+		{name: "maximum not a number", schema: `{"maximum": null}`, wantErr: "at /maximum: must be a number"},
+		{name: "exclusiveMinimum not a number", schema: `{"exclusiveMinimum": true}`, wantErr: "at /exclusiveMinimum: must be a number"},
+		{name: "exclusiveMaximum not a number", schema: `{"exclusiveMaximum": []}`, wantErr: "at /exclusiveMaximum: must be a number"},
+		{name: "multipleOf not a number", schema: `{"multipleOf": "2"}`, wantErr: "at /multipleOf: must be a number"},
+		{name: "zero multipleOf", schema: `{"multipleOf": 0}`, wantErr: "at /multipleOf: must be greater than 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Compile: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Compile error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		want   []Error
+	}{
+		{name: "true accepts anything", schema: `true`, doc: `{"a": [1]}`},
+		{name: "false rejects everything", schema: `false`, doc: `null`, want: []Error{{"", "is not allowed"}}},
+
+		{name: "type", schema: `{"type": "string"}`, doc: `"a"`},
+		{name: "type mismatch", schema: `{"type": "string"}`, doc: `1`, want: []Error{{"", "must be of type string"}}},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, doc: `null`},
+		{name: "type list mismatch", schema: `{"type": ["string", "null"]}`, doc: `false`, want: []Error{{"", "must be of type string or null"}}},
+		{name: "integer", schema: `{"type": "integer"}`, doc: `2.0`},
+		{name: "integer mismatch", schema: `{"type": "integer"}`, doc: `2.5`, want: []Error{{"", "must be of type integer"}}},
+		{name: "number", schema: `{"type": "number"}`, doc: `2.5`},
+		{name: "boolean", schema: `{"type": "boolean"}`, doc: `true`},
+		{name: "array", schema: `{"type": "array"}`, doc: `[]`},
+		{name: "object mismatch", schema: `{"type": "object"}`, doc: `[]`, want: []Error{{"", "must be of type object"}}},
+		{name: "type mismatch skips other keywords", schema: `{"type": "string", "enum": ["a"]}`, doc: `1`, want: []Error{{"", "must be of type string"}}},
+
+		{name: "enum", schema: `{"enum": ["a", 1, {"b": [2]}]}`, doc: `{"b": [2]}`},
+		{name: "enum mismatch", schema: `{"enum": ["a", 1]}`, doc: `"b"`, want: []Error{{"", `must be one of ["a",1]`}}},
+		{name: "const", schema: `{"const": {"a": 1, "b": 2}}`, doc: `{"b": 2, "a": 1}`},
+		{name: "const mismatch", schema: `{"const": 1}`, doc: `1.5`, want: []Error{{"", "must be 1"}}},
+
+		{name: "properties", schema: `{"properties": {"a": {"type": "string"}}}`, doc: `{"a": "x", "b": 1}`},
+		{name: "property mismatch", schema: `{"properties": {"a": {"type": "string"}}}`, doc: `{"a": 1}`, want: []Error{{"a", "must be of type string"}}},
+		{
+			name:   "required",
+			schema: `{"required": ["a", "b"]}`, doc: `{"a": null}`,
+			want: []Error{{"b", "is required"}},
+		},
+		{name: "additionalProperties false", schema: `{"properties": {"a": {}}, "additionalProperties": false}`, doc: `{"a": 1, "b": 2}`, want: []Error{{"b", "is not allowed"}}},
+		{name: "additionalProperties schema", schema: `{"additionalProperties": {"type": "integer"}}`, doc: `{"a": 1, "b": "2"}`, want: []Error{{"b", "must be of type integer"}}},
+
+		{name: "items", schema: `{"items": {"type": "string"}}`, doc: `["a", "b"]`},
+		{name: "items mismatch", schema: `{"items": {"type": "string"}}`, doc: `["a", 2, "c", null]`, want: []Error{{"[1]", "must be of type string"}, {"[3]", "must be of type string"}}},
+		{name: "minItems", schema: `{"minItems": 2}`, doc: `[1]`, want: []Error{{"", "must have at least 2 items"}}},
+		{name: "maxItems", schema: `{"maxItems": 1}`, doc: `[1, 2]`, want: []Error{{"", "must have at most 1 items"}}},
+		{name: "uniqueItems", schema: `{"uniqueItems": true}`, doc: `[1, {"a": 1}, 2]`},
+		{name: "uniqueItems duplicate", schema: `{"uniqueItems": true}`, doc: `[{"a": 1}, 2, {"a": 1}]`, want: []Error{{"", "must not contain duplicate items"}}},
+
+		{name: "minLength counts characters", schema: `{"minLength": 2}`, doc: `"é"`, want: []Error{{"", "must be at least 2 characters"}}},
+		{name: "maxLength counts characters", schema: `{"maxLength": 2}`, doc: `"éé"`},
+		{name: "maxLength", schema: `{"maxLength": 2}`, doc: `"abc"`, want: []Error{{"", "must be at most 2 characters"}}},
+		{name: "pattern", schema: `{"pattern": "^[0-9]{5}$"}`, doc: `"1234"`, want: []Error{{"", "must match ^[0-9]{5}$"}}},
+		{name: "format email", schema: `{"format": "email"}`, doc: `"a@example.com"`},
+		{name: "format email mismatch", schema: `{"format": "email"}`, doc: `"Ann <a@example.com>"`, want: []Error{{"", "must be a valid email"}}},
+		{name: "format date-time", schema: `{"format": "date-time"}`, doc: `"2024-01-02T03:04:05Z"`},
+		{name: "format date mismatch", schema: `{"format": "date"}`, doc: `"2024-13-01"`, want: []Error{{"", "must be a valid date"}}},
+		{name: "format uri mismatch", schema: `{"format": "uri"}`, doc: `"example.com"`, want: []Error{{"", "must be a valid uri"}}},
+		{name: "string keywords ignore other types", schema: `{"minLength": 5, "pattern": "a"}`, doc: `1`},
+
+		{name: "minimum", schema: `{"minimum": 1}`, doc: `1`},
+		{name: "minimum mismatch", schema: `{"minimum": 1}`, doc: `0.5`, want: []Error{{"", "must be >= 1"}}},
+		{name: "maximum mismatch", schema: `{"maximum": 1}`, doc: `2`, want: []Error{{"", "must be <= 1"}}},
+		{name: "exclusiveMinimum mismatch", schema: `{"exclusiveMinimum": 1}`, doc: `1`, want: []Error{{"", "must be > 1"}}},
+		{name: "exclusiveMaximum mismatch", schema: `{"exclusiveMaximum": 1}`, doc: `1`, want: []Error{{"", "must be < 1"}}},
+		{name: "multipleOf", schema: `{"multipleOf": 0.1}`, doc: `0.3`},
+		{name: "multipleOf mismatch", schema: `{"multipleOf": 2}`, doc: `3`, want: []Error{{"", "must be a multiple of 2"}}},
+
+		{
+			name: "nested paths",
+			schema: `{
+				"type": "object",
+				"required": ["addr"],
+				"properties": {
+					"addr": {
+						"type": "object",
+						"required": ["city"],
+						"properties": {"zip": {"type": "string", "pattern": "^[0-9]+$"}}
+					},
+					"tags": {"type": "array", "items": {"type": "string", "maxLength": 3}},
+					"rooms": {"items": {"properties": {"size": {"minimum": 1}}}}
+				}
+			}`,
+			doc: `{"addr": {"zip": "12a"}, "tags": ["ok", "toolong"], "rooms": [{"size": 2}, {"size": 0}]}`,
+			want: []Error{
+				{"addr.city", "is required"},
+				{"addr.zip", "must match ^[0-9]+$"},
+				{"rooms[1].size", "must be >= 1"},
+				{"tags[1]", "must be at most 3 characters"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := s.Validate([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidJSON(t *testing.T) {
+	s, err := Compile([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Validate([]byte(`{"a":`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidJSON)
+	}
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import "os"
+
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverStdout = "stdout"
+)
+
+type Config struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	// File is where the file driver appends messages.
+	File string
+}
+
+// LoadConfig reads the MAIL_* variables. Driver stays empty when MAIL_DRIVER
+// is not set, the caller decides whether stdout is acceptable.
+func LoadConfig() *Config {
+	cfg := &Config{
+		Driver:       os.Getenv("MAIL_DRIVER"),
+		From:         os.Getenv("MAIL_FROM"),
+		SMTPHost:     os.Getenv("MAIL_SMTP_HOST"),
+		SMTPPort:     os.Getenv("MAIL_SMTP_PORT"),
+		SMTPUsername: os.Getenv("MAIL_SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("MAIL_SMTP_PASSWORD"),
+		File:         os.Getenv("MAIL_FILE"),
+	}
+	if cfg.From == "" {
+		cfg.From = "no-reply@localhost"
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "587"
+	}
+	if cfg.File == "" {
+		cfg.File = "mail.log"
+	}
+	return cfg
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import "errors"
+
+var ErrUnsupportedDriver = errors.New("unsupported mail driver")
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain text mail. Implementations must be safe for
+// concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New builds the mailer selected by cfg.Driver.
+func New(cfg *Config) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case DriverFile:
+		return NewFileMailer(cfg.File, cfg.From)
+	case DriverStdout:
+		return NewStdoutMailer(cfg.From), nil
+	}
+	return nil, ErrUnsupportedDriver
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterMailer writes every message to an io.Writer instead of sending it,
+// for local development and tests.
+type WriterMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewWriterMailer(w io.Writer, from string) *WriterMailer {
+	return &WriterMailer{w: w, from: from}
+}
+
+func NewStdoutMailer(from string) *WriterMailer {
+	return NewWriterMailer(os.Stdout, from)
+}
+
+// NewFileMailer appends messages to path, creating it if needed.
+func NewFileMailer(path, from string) (*WriterMailer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterMailer(f, from), nil
+}
+
+func (m *WriterMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.w.Write(format(m.from, msg)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(m.w, "\r\n.\r\n")
+	return err
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
+	"golang-rest-user/cli"
 	"golang-rest-user/provider/mySqlProvider"
 	"golang-rest-user/provider/redisProvider"
 	"golang-rest-user/provider/routesProvider"
 	"golang-rest-user/provider/serviceProvider"
 	"golang-rest-user/provider/tenantProvider"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
 
 	redisProvider.Init()
 	mySqlProvider.Init()
@@ -18,6 +24,8 @@ func main() {
 	r := gin.Default()
 
 	tenantProvider.Init()
+	tenantProvider.Subscribe(context.Background())
+	tenantProvider.StartTrashPurge(context.Background())
 
 	routesProvider.Init(r)
 
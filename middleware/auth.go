@@ -17,6 +17,7 @@ func AuthMiddleware(jwtManager *security.Manager) gin.HandlerFunc {
 
 		if !strings.HasPrefix(auth, "Bearer ") {
 			response.Error(c, response.CodeBadRequest, "Unauthorized", nil, http.StatusUnauthorized)
+			c.Abort()
 			return
 		}
 
@@ -25,22 +26,56 @@ func AuthMiddleware(jwtManager *security.Manager) gin.HandlerFunc {
 		claims, err := jwtManager.ParseToken(tokenStr)
 		if err != nil {
 			response.Error(c, response.CodeBadRequest, "Unauthorized", nil, http.StatusUnauthorized)
+			c.Abort()
 			return
 		}
 
 		if err != nil || claims.Type != enums.TokenTypeAccess {
 			response.Error(c, response.CodeBadRequest, "Invalid access token", nil, http.StatusUnauthorized)
+			c.Abort()
 			return
 		}
 		tokenVer := claims.Version
 		currentVer := redisProvider.GetTokenVer(claims.UserID, claims.TenantCode)
 		if tokenVer != currentVer {
 			response.Error(c, response.CodeBadRequest, "Unauthorized", nil, http.StatusUnauthorized)
+			c.Abort()
 			return
 		}
+		if claims.SessionID != "" {
+			// the session may have been revoked from another device
+			alive, err := redisProvider.FamilyExists(claims.TenantCode, claims.UserID, claims.SessionID)
+			if err != nil || !alive {
+				response.Error(c, response.CodeBadRequest, "Unauthorized", nil, http.StatusUnauthorized)
+				c.Abort()
+				return
+			}
+		}
 		c.Set("user_id", claims.UserID)
-		c.Set("tenant_code", claims.TenantCode)
+		c.Set("token_tenant_code", claims.TenantCode)
+		c.Set("session_id", claims.SessionID)
+		role := claims.UserRole
+		if role == "" {
+			// issued before tenant roles existed
+			role = enums.TenantRoleMember
+		}
+		c.Set("user_role", string(role))
 
 		c.Next()
 	}
 }
+
+// RequireTenantRole must run after AuthMiddleware.
+func RequireTenantRole(roles ...enums.TenantRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := enums.TenantRole(c.GetString("user_role"))
+		for _, role := range roles {
+			if current == role {
+				c.Next()
+				return
+			}
+		}
+		response.Error(c, response.CodeForbidden, "Forbidden", nil, http.StatusForbidden)
+		c.Abort()
+	}
+}
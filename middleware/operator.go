@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/response"
+	"golang-rest-user/security"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OperatorAuthMiddleware accepts only platform operator access tokens.
+func OperatorAuthMiddleware(jwtManager *security.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth := c.GetHeader("Authorization")
+
+		if !strings.HasPrefix(auth, "Bearer ") {
+			response.Error(c, response.CodeUnauthorized, "Unauthorized", nil, http.StatusUnauthorized)
+			c.Abort()
+			return
+		}
+
+		claims, err := jwtManager.ParseToken(strings.TrimPrefix(auth, "Bearer "))
+		if err != nil || claims == nil || claims.Type != enums.TokenTypeOperatorAccess {
+			response.Error(c, response.CodeUnauthorized, "Invalid operator token", nil, http.StatusUnauthorized)
+			c.Abort()
+			return
+		}
+
+		if claims.Version != redisProvider.GetOperatorTokenVer(claims.UserID) {
+			response.Error(c, response.CodeUnauthorized, "Unauthorized", nil, http.StatusUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Set("operator_id", claims.UserID)
+		c.Set("operator_role", string(claims.Role))
+
+		c.Next()
+	}
+}
+
+// RequireOperatorRole must run after OperatorAuthMiddleware.
+func RequireOperatorRole(roles ...enums.OperatorRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := enums.OperatorRole(c.GetString("operator_role"))
+		for _, role := range roles {
+			if current == role {
+				c.Next()
+				return
+			}
+		}
+		response.Error(c, response.CodeForbidden, "Forbidden", nil, http.StatusForbidden)
+		c.Abort()
+	}
+}
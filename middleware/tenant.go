@@ -1,22 +1,132 @@
 package middleware
 
 import (
-	//"log"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"strings"
 
+	"golang-rest-user/config"
+	"golang-rest-user/provider/tenantProvider"
 	"golang-rest-user/response"
 
 	"github.com/gin-gonic/gin"
 )
 
-func TenantDBMiddleware() gin.HandlerFunc {
+// TenantResolver extracts a tenant code from the request, or "" if the
+// strategy does not apply to it.
+type TenantResolver func(c *gin.Context) string
+
+func HeaderResolver() TenantResolver {
+	return func(c *gin.Context) string {
+		return strings.TrimSpace(c.GetHeader("X-Tenant-Code"))
+	}
+}
+
+// SubdomainResolver maps acme.<baseDomain> to tenant acme.
+func SubdomainResolver(baseDomain string) TenantResolver {
+	suffix := "." + strings.TrimPrefix(baseDomain, ".")
+	return func(c *gin.Context) string {
+		host := strings.ToLower(c.Request.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+		sub := strings.TrimSuffix(host, suffix)
+		if sub == "" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// PathResolver reads the tenant from the /t/:tenant route prefix.
+func PathResolver() TenantResolver {
+	return func(c *gin.Context) string {
+		return c.Param("tenant")
+	}
+}
+
+// ClaimResolver reads the tenant_code claim set by AuthMiddleware.
+func ClaimResolver() TenantResolver {
+	return func(c *gin.Context) string {
+		return c.GetString("token_tenant_code")
+	}
+}
+
+func NewTenantResolvers(cfg config.TenantResolverConfig) []TenantResolver {
+	var resolvers []TenantResolver
+	for _, strategy := range cfg.Strategies {
+		switch strategy {
+		case config.TenantResolverHeader:
+			resolvers = append(resolvers, HeaderResolver())
+		case config.TenantResolverSubdomain:
+			if cfg.BaseDomain == "" {
+				log.Println("subdomain tenant resolver needs TENANT_BASE_DOMAIN, skipped")
+				continue
+			}
+			resolvers = append(resolvers, SubdomainResolver(cfg.BaseDomain))
+		case config.TenantResolverPath:
+			resolvers = append(resolvers, PathResolver())
+		case config.TenantResolverJWT:
+			resolvers = append(resolvers, ClaimResolver())
+		default:
+			log.Printf("unknown tenant resolver %q, skipped", strategy)
+		}
+	}
+	return resolvers
+}
+
+// ResolveTenant determines the tenant of the request, rejects unknown or
+// unavailable tenants and stores the acquired *TenantInfo as "tenant_info"
+// and its code as "tenant_code". On authenticated routes it must run after
+// AuthMiddleware, and the resolved tenant must match the token's tenant.
+func ResolveTenant(resolvers ...TenantResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tenantCode := c.GetHeader("X-Tenant-Code")
+		tenantCode := ""
+		for _, resolve := range resolvers {
+			if tenantCode = resolve(c); tenantCode != "" {
+				break
+			}
+		}
 		if tenantCode == "" {
-			response.Error(c, response.CodeBadRequest, "X-Tenant-Code header is required", nil, http.StatusInternalServerError)
+			response.Error(c, response.CodeBadRequest, "tenant could not be resolved from the request", nil, http.StatusBadRequest)
+			c.Abort()
 			return
 		}
-		c.Set("TENANT_CODE", tenantCode)
-		c.Next()
+
+		if tokenTenant, ok := c.Get("token_tenant_code"); ok && tokenTenant != tenantCode {
+			response.Error(c, response.CodeForbidden, "token does not belong to this tenant", nil, http.StatusForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant_code", tenantCode)
+		withTenant(c, tenantCode)
 	}
 }
+
+// withTenant holds a reference on the tenant for the rest of the chain so its
+// pool cannot be closed while handlers are still using it.
+func withTenant(c *gin.Context, tenantCode string) {
+	info, release, err := tenantProvider.Acquire(tenantCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, tenantProvider.ErrTenantNotFound):
+			response.Error(c, response.CodeTenantNotFound, err.Error(), nil, http.StatusNotFound)
+		case errors.Is(err, tenantProvider.ErrTenantSuspended):
+			response.Error(c, response.CodeTenantSuspended, err.Error(), nil, http.StatusLocked)
+		default:
+			response.Error(c, response.CodeTenantUnavailable, err.Error(), nil, http.StatusLocked)
+		}
+		c.Abort()
+		return
+	}
+	defer release()
+
+	c.Set("tenant_info", info)
+	c.Next()
+}
@@ -0,0 +1,235 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const lockName = "schema_migrations"
+
+var ErrDirty = errors.New("schema is dirty")
+
+// Status is the schema version of one database.
+type Status struct {
+	Version int  `json:"version"`
+	Latest  int  `json:"latest"`
+	Pending int  `json:"pending"`
+	Dirty   bool `json:"dirty"`
+}
+
+// Result describes what a single Up or Down call changed.
+type Result struct {
+	From    int   `json:"from"`
+	To      int   `json:"to"`
+	Applied []int `json:"applied"`
+}
+
+// Migrator applies a migration set to one database and records every applied
+// version in its schema_migrations table. A version is marked dirty before
+// it runs and clean afterwards; MySQL commits DDL implicitly, so a failure
+// halfway through leaves the version dirty until it is fixed by hand and
+// cleared with Force.
+type Migrator struct {
+	db  *sql.DB
+	set []Migration
+}
+
+func NewMigrator(db *sql.DB, set []Migration) *Migrator {
+	return &Migrator{db: db, set: set}
+}
+
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	version, dirty, err := currentVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		Version: version,
+		Latest:  Latest(m.set),
+		Pending: len(m.pending(version)),
+		Dirty:   dirty,
+	}, nil
+}
+
+// Up applies every migration newer than the current version.
+func (m *Migrator) Up(ctx context.Context) (*Result, error) {
+	var result *Result
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		version, dirty, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, version)
+		}
+		result = &Result{From: version, To: version, Applied: []int{}}
+		for _, mig := range m.pending(version) {
+			if err := apply(ctx, conn, mig, mig.Up, true); err != nil {
+				return err
+			}
+			result.To = mig.Version
+			result.Applied = append(result.Applied, mig.Version)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Down reverts the given number of applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) (*Result, error) {
+	var result *Result
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		version, dirty, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, version)
+		}
+		result = &Result{From: version, To: version, Applied: []int{}}
+		for i := len(m.set) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.set[i]
+			if mig.Version > version {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+			}
+			if err := apply(ctx, conn, mig, mig.Down, false); err != nil {
+				return err
+			}
+			result.Applied = append(result.Applied, mig.Version)
+			steps--
+			if result.To, _, err = currentVersion(ctx, conn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Force records version as the clean current version without running any
+// SQL, after a dirty migration has been repaired manually.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version > ?", version); err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		name := ""
+		for _, mig := range m.set {
+			if mig.Version == version {
+				name = mig.Name
+			}
+		}
+		_, err := conn.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, 0, ?) "+
+				"ON DUPLICATE KEY UPDATE dirty = 0",
+			version, name, time.Now().UTC(),
+		)
+		return err
+	})
+}
+
+func (m *Migrator) pending(version int) []Migration {
+	var result []Migration
+	for _, mig := range m.set {
+		if mig.Version > version {
+			result = append(result, mig)
+		}
+	}
+	return result
+}
+
+// locked runs fn on a single connection holding a named lock, so two
+// instances starting at the same time do not migrate the same database twice.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// named locks are server-wide, the master and tenant databases on one
+	// server must not wait for each other. MD5 keeps it within 64 chars.
+	var name string
+	if err := conn.QueryRowContext(ctx, "SELECT CONCAT(?, ':', MD5(DATABASE()))", lockName).Scan(&name); err != nil {
+		return err
+	}
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", name).Scan(&acquired); err != nil {
+		return err
+	}
+	if acquired.Int64 != 1 {
+		return errors.New("timed out waiting for migration lock")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `schema_migrations` ("+
+		"`version` bigint NOT NULL,"+
+		"`name` varchar(255) NOT NULL,"+
+		"`dirty` tinyint(1) NOT NULL DEFAULT 0,"+
+		"`applied_at` datetime(3) NOT NULL,"+
+		"PRIMARY KEY (`version`)"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	return err
+}
+
+func currentVersion(ctx context.Context, conn *sql.Conn) (int, bool, error) {
+	var version int
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations ORDER BY version DESC LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+func apply(ctx context.Context, conn *sql.Conn, mig Migration, content string, up bool) error {
+	if up {
+		_, err := conn.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, 1, ?)",
+			mig.Version, mig.Name, time.Now().UTC(),
+		)
+		if err != nil {
+			return err
+		}
+	} else if _, err := conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = 1 WHERE version = ?", mig.Version); err != nil {
+		return err
+	}
+
+	for _, stmt := range statements(content) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	if up {
+		_, err := conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = 0 WHERE version = ?", mig.Version)
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+	return err
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `tenant_provisioning_steps`;
+DROP TABLE IF EXISTS `tenant_provisionings`;
+DROP TABLE IF EXISTS `operators`;
+DROP TABLE IF EXISTS `tenants`;
@@ -0,0 +1,65 @@
+CREATE TABLE IF NOT EXISTS `tenants` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `code` varchar(45) NULL,
+  `name` varchar(255) NOT NULL,
+  `db_user` varchar(255) NULL,
+  `db_pass` varchar(255) NULL,
+  `db_host` varchar(50) NULL,
+  `db_port` varchar(50) NULL,
+  `db_name` varchar(50) NULL,
+  `status` enum('active','inactive','provisioning','failed') DEFAULT 'active',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_tenants_code` (`code`),
+  UNIQUE INDEX `idx_tenants_db_name` (`db_name`),
+  INDEX `idx_tenants_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `operators` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `username` varchar(255) NOT NULL,
+  `password` varchar(255) NOT NULL,
+  `full_name` varchar(255) NULL,
+  `role` enum('admin','read_only') DEFAULT 'read_only',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_operators_username` (`username`),
+  INDEX `idx_operators_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `tenant_provisionings` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `tenant_id` bigint unsigned NOT NULL,
+  `tenant_code` varchar(45) NULL,
+  `status` varchar(20) NULL,
+  `attempt` bigint NULL,
+  `error` text NULL,
+  `started_at` datetime(3) NULL,
+  `finished_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_tenant_provisionings_tenant_id` (`tenant_id`),
+  INDEX `idx_tenant_provisionings_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `tenant_provisioning_steps` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `provisioning_id` bigint unsigned NOT NULL,
+  `attempt` bigint NULL,
+  `name` varchar(50) NULL,
+  `status` varchar(20) NULL,
+  `error` text NULL,
+  `started_at` datetime(3) NULL,
+  `finished_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_tenant_provisioning_steps_provisioning_id` (`provisioning_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,13 @@
+CREATE TABLE `signing_keys` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `kid` varchar(64) NOT NULL,
+  `algorithm` varchar(10) NOT NULL,
+  `private_key` text NOT NULL,
+  `public_key` text NOT NULL,
+  `status` varchar(20) NULL,
+  `created_at` datetime(3) NULL,
+  `retired_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_signing_keys_kid` (`kid`),
+  INDEX `idx_signing_keys_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE `tenants` DROP COLUMN `settings`;
@@ -0,0 +1 @@
+ALTER TABLE `tenants` ADD COLUMN `settings` JSON NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `user_zones`;
+DROP TABLE IF EXISTS `zones`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `username` varchar(255) NOT NULL,
+  `password` varchar(255) NOT NULL,
+  `full_name` varchar(255) NULL,
+  `phone` varchar(50) NULL,
+  `position` varchar(255) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_users_username` (`username`),
+  INDEX `idx_users_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `zones` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(255) NULL,
+  `type` varchar(255) NULL,
+  `path` varchar(255) NULL,
+  `level` bigint NULL,
+  `parent_id` bigint unsigned NULL,
+  `metadata` JSON NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_zones_path` (`path`),
+  INDEX `idx_zones_parent_id` (`parent_id`),
+  INDEX `idx_zones_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_zones` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `zone_id` bigint unsigned NOT NULL,
+  `permission` longtext NULL,
+  PRIMARY KEY (`id`, `user_id`, `zone_id`),
+  INDEX `idx_user_zones_zone_id` (`zone_id`),
+  INDEX `idx_user_zones_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+ALTER TABLE `users`
+  ADD COLUMN `email_verified` boolean NOT NULL DEFAULT false,
+  ADD COLUMN `email_verified_at` datetime(3) NULL;
+
+-- accounts created before verification existed keep working
+UPDATE `users` SET `email_verified` = true, `email_verified_at` = `created_at`;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `user_recovery_codes`;
+
+ALTER TABLE `users`
+  DROP COLUMN `mfa_enabled_at`,
+  DROP COLUMN `mfa_secret`,
+  DROP COLUMN `mfa_enabled`;
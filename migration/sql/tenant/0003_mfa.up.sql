@@ -0,0 +1,14 @@
+ALTER TABLE `users`
+  ADD COLUMN `mfa_enabled` boolean NOT NULL DEFAULT false,
+  ADD COLUMN `mfa_secret` varchar(255) NULL,
+  ADD COLUMN `mfa_enabled_at` datetime(3) NULL;
+
+CREATE TABLE IF NOT EXISTS `user_recovery_codes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `code_hash` varchar(64) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `used_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_user_recovery_codes_user_code` (`user_id`, `code_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
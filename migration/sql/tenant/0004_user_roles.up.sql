@@ -0,0 +1,8 @@
+ALTER TABLE `users`
+  ADD COLUMN `role` varchar(50) NOT NULL DEFAULT 'member',
+  ADD INDEX `idx_users_role` (`role`);
+
+-- nobody is promoted here, any account may have come from public sign-up.
+-- Operators assign the first tenant_admin explicitly:
+--   golang-rest-user user set-role -tenant <code> -username <name>
+-- or, for a tenant without users, user create-admin.
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `zone_roles` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(50) NOT NULL,
+  `description` varchar(255) NULL,
+  `actions` JSON NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_zone_roles_name` (`name`),
+  INDEX `idx_zone_roles_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
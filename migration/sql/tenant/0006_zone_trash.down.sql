@@ -0,0 +1,9 @@
+ALTER TABLE `user_zones`
+  DROP INDEX `idx_user_zones_trash_id`,
+  DROP COLUMN `trash_id`;
+
+ALTER TABLE `zones`
+  DROP INDEX `idx_zones_trash_id`,
+  DROP COLUMN `trash_id`;
+
+DROP TABLE IF EXISTS `trashed_zones`;
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS `trashed_zones` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `zone_id` bigint unsigned NOT NULL,
+  `deleted_by` bigint unsigned NULL,
+  `trashed_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_trashed_zones_zone_id` (`zone_id`),
+  INDEX `idx_trashed_zones_deleted_by` (`deleted_by`),
+  INDEX `idx_trashed_zones_trashed_at` (`trashed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- every trashed zone and share points at the trash entry it went with
+ALTER TABLE `zones`
+  ADD COLUMN `trash_id` bigint unsigned NULL,
+  ADD INDEX `idx_zones_trash_id` (`trash_id`);
+
+ALTER TABLE `user_zones`
+  ADD COLUMN `trash_id` bigint unsigned NULL,
+  ADD INDEX `idx_user_zones_trash_id` (`trash_id`);
+
+-- zones deleted so far: the top of every deleted subtree goes to the trash
+INSERT INTO `trashed_zones` (`zone_id`, `deleted_by`, `trashed_at`)
+SELECT z.`id`, NULL, z.`deleted_at`
+FROM `zones` z
+LEFT JOIN `zones` p ON p.`id` = z.`parent_id`
+WHERE z.`deleted_at` IS NOT NULL
+  AND (p.`id` IS NULL OR p.`deleted_at` IS NULL OR p.`deleted_at` <> z.`deleted_at`);
+
+-- a subtree was deleted in one statement, so its zones share the deleted_at
+-- of its top
+UPDATE `zones` z
+JOIN `trashed_zones` t
+JOIN `zones` top ON top.`id` = t.`zone_id`
+SET z.`trash_id` = t.`id`
+WHERE z.`path` LIKE CONCAT(top.`path`, '%') AND z.`deleted_at` = t.`trashed_at`;
+
+-- and the shares they left behind go with them
+UPDATE `user_zones` uz
+JOIN `zones` z ON z.`id` = uz.`zone_id`
+SET uz.`deleted_at` = z.`deleted_at`, uz.`trash_id` = z.`trash_id`
+WHERE z.`trash_id` IS NOT NULL AND uz.`deleted_at` IS NULL;
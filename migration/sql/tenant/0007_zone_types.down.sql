@@ -0,0 +1,3 @@
+DROP INDEX `idx_zones_type` ON `zones`;
+
+DROP TABLE IF EXISTS `zone_types`;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `zone_types` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `uuid` varchar(255) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(100) NOT NULL,
+  `description` varchar(255) NULL,
+  `metadata_schema` JSON NULL,
+  `allowed_parents` JSON NULL,
+  `allow_root` boolean NOT NULL DEFAULT true,
+  `max_depth` bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_zone_types_name` (`name`),
+  INDEX `idx_zone_types_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE INDEX `idx_zones_type` ON `zones` (`type`);
@@ -0,0 +1,13 @@
+package models
+
+import "golang-rest-user/enums"
+
+// Operator is a platform staff account stored in the master DB. Operators
+// manage tenants and never belong to a tenant themselves.
+type Operator struct {
+	BaseModel
+	Username string             `gorm:"size:255;uniqueIndex;not null" json:"username"`
+	Password string             `gorm:"size:255;not null" json:"-"`
+	FullName string             `gorm:"size:255" json:"full_name"`
+	Role     enums.OperatorRole `gorm:"type:enum('admin', 'read_only'); default:'read_only'" json:"role"`
+}
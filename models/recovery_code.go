@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserRecoveryCode is a one-time MFA code for when the authenticator is lost.
+// Only the SHA-256 of the code is stored.
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"-"`
+	UserID    uint       `gorm:"not null; uniqueIndex:idx_user_recovery_codes_user_code" json:"-"`
+	CodeHash  string     `gorm:"size:64; not null; uniqueIndex:idx_user_recovery_codes_user_code" json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"golang-rest-user/enums"
+	"time"
+)
+
+// SigningKey is a JWT signing key pair. The private key is stored AES-GCM
+// encrypted; retired keys are kept so tokens they signed still verify.
+type SigningKey struct {
+	ID         uint                   `gorm:"primaryKey" json:"-"`
+	Kid        string                 `gorm:"size:64; uniqueIndex; not null" json:"kid"`
+	Algorithm  string                 `gorm:"size:10; not null" json:"algorithm"`
+	PrivateKey string                 `gorm:"type:text; not null" json:"-"`
+	PublicKey  string                 `gorm:"type:text; not null" json:"public_key"`
+	Status     enums.SigningKeyStatus `gorm:"size:20; index" json:"status"`
+	CreatedAt  time.Time              `json:"created_at"`
+	RetiredAt  *time.Time             `json:"retired_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"golang-rest-user/enums"
+	"time"
+)
+
+// TenantProvisioning tracks the creation of a tenant database. A failed job
+// can be retried, each retry records its steps under a new Attempt.
+type TenantProvisioning struct {
+	BaseModel
+	TenantID   uint                     `gorm:"index; not null" json:"tenant_id"`
+	TenantCode string                   `gorm:"size:45" json:"tenant_code"`
+	Status     enums.ProvisioningStatus `gorm:"size:20" json:"status"`
+	Attempt    int                      `json:"attempt"`
+	Error      string                   `gorm:"type:text" json:"error"`
+	StartedAt  *time.Time               `json:"started_at"`
+	FinishedAt *time.Time               `json:"finished_at"`
+	Steps      []TenantProvisioningStep `gorm:"foreignKey:ProvisioningID" json:"steps"`
+}
+
+type TenantProvisioningStep struct {
+	ID             uint                     `gorm:"primaryKey" json:"-"`
+	ProvisioningID uint                     `gorm:"index; not null" json:"-"`
+	Attempt        int                      `json:"attempt"`
+	Name           enums.ProvisioningStep   `gorm:"size:50" json:"name"`
+	Status         enums.ProvisioningStatus `gorm:"size:20" json:"status"`
+	Error          string                   `gorm:"type:text" json:"error"`
+	StartedAt      time.Time                `json:"started_at"`
+	FinishedAt     *time.Time               `json:"finished_at"`
+}
@@ -0,0 +1,79 @@
+package models
+
+// TenantSettings are per-tenant policies, stored as JSON on the tenant row.
+// Zero values mean "use the default".
+type TenantSettings struct {
+	Login LoginSettings `json:"login"`
+	Zones ZoneSettings  `json:"zones"`
+}
+
+// LoginSettings control who may log in and the brute-force protection.
+// Failures are counted per username and per client IP over a sliding window.
+type LoginSettings struct {
+	// MaxFailures locks the account once reached within FailureWindowSeconds.
+	MaxFailures          int `json:"max_failures"`
+	FailureWindowSeconds int `json:"failure_window_seconds"`
+	LockoutSeconds       int `json:"lockout_seconds"`
+	// MaxFailuresPerIP rejects further attempts from one IP, for any user.
+	MaxFailuresPerIP int `json:"max_failures_per_ip"`
+	// After DelayAfterFailures failures every attempt must wait
+	// DelayBaseSeconds, doubling with each further failure up to
+	// DelayMaxSeconds.
+	DelayAfterFailures int `json:"delay_after_failures"`
+	DelayBaseSeconds   int `json:"delay_base_seconds"`
+	DelayMaxSeconds    int `json:"delay_max_seconds"`
+	// AllowUnverifiedEmail lets users log in before verifying their email.
+	AllowUnverifiedEmail bool `json:"allow_unverified_email"`
+	// RequireMFA makes users without MFA enroll before their first login.
+	RequireMFA bool `json:"require_mfa"`
+}
+
+func (s LoginSettings) WithDefaults() LoginSettings {
+	defaults := LoginSettings{
+		MaxFailures:          10,
+		FailureWindowSeconds: 900,
+		LockoutSeconds:       900,
+		MaxFailuresPerIP:     100,
+		DelayAfterFailures:   3,
+		DelayBaseSeconds:     1,
+		DelayMaxSeconds:      60,
+		AllowUnverifiedEmail: s.AllowUnverifiedEmail,
+		RequireMFA:           s.RequireMFA,
+	}
+	if s.MaxFailures > 0 {
+		defaults.MaxFailures = s.MaxFailures
+	}
+	if s.FailureWindowSeconds > 0 {
+		defaults.FailureWindowSeconds = s.FailureWindowSeconds
+	}
+	if s.LockoutSeconds > 0 {
+		defaults.LockoutSeconds = s.LockoutSeconds
+	}
+	if s.MaxFailuresPerIP > 0 {
+		defaults.MaxFailuresPerIP = s.MaxFailuresPerIP
+	}
+	if s.DelayAfterFailures > 0 {
+		defaults.DelayAfterFailures = s.DelayAfterFailures
+	}
+	if s.DelayBaseSeconds > 0 {
+		defaults.DelayBaseSeconds = s.DelayBaseSeconds
+	}
+	if s.DelayMaxSeconds > 0 {
+		defaults.DelayMaxSeconds = s.DelayMaxSeconds
+	}
+	return defaults
+}
+
+type ZoneSettings struct {
+	// TrashRetentionDays is how long deleted zones can be restored before
+	// they are purged for good.
+	TrashRetentionDays int `json:"trash_retention_days"`
+}
+
+func (s ZoneSettings) WithDefaults() ZoneSettings {
+	defaults := ZoneSettings{TrashRetentionDays: 30}
+	if s.TrashRetentionDays > 0 {
+		defaults.TrashRetentionDays = s.TrashRetentionDays
+	}
+	return defaults
+}
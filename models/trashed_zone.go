@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TrashedZone is a deleted subtree waiting in the trash. Its zones and their
+// user_zones rows are soft-deleted and carry its ID as their trash_id, which
+// tells them apart from zones trashed earlier inside the same subtree.
+type TrashedZone struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	ZoneID    uint      `gorm:"not null;uniqueIndex" json:"-"`
+	DeletedBy *uint     `gorm:"index" json:"deleted_by"`
+	TrashedAt time.Time `gorm:"not null;index" json:"trashed_at"`
+}
@@ -1,10 +1,23 @@
 package models
 
+import (
+	"golang-rest-user/enums"
+	"time"
+)
+
 type User struct {
 	BaseModel
-	Username string `gorm:"size:255;uniqueIndex;not null" json:"username"`
-	Password string `gorm:"size:255;not null" json:"password"`
-	FullName string `gorm:"size:255" json:"full_name"`
-	Phone    string `gorm:"size:50" json:"phone"`
-	Position string `gorm:"size:255" json:"position"`
+	Username string           `gorm:"size:255;uniqueIndex;not null" json:"username"`
+	Password string           `gorm:"size:255;not null" json:"password"`
+	FullName string           `gorm:"size:255" json:"full_name"`
+	Phone    string           `gorm:"size:50" json:"phone"`
+	Position string           `gorm:"size:255" json:"position"`
+	Role     enums.TenantRole `gorm:"size:50;index;not null;default:member" json:"role"`
+
+	EmailVerified   bool       `gorm:"not null;default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	MFAEnabled   bool       `gorm:"column:mfa_enabled;not null;default:false" json:"mfa_enabled"`
+	MFASecret    string     `gorm:"column:mfa_secret;size:255" json:"-"`
+	MFAEnabledAt *time.Time `gorm:"column:mfa_enabled_at" json:"mfa_enabled_at"`
 }
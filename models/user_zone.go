@@ -7,4 +7,6 @@ type UserZone struct {
 	UserID     uint                 `gorm:"primaryKey" json:"user_id"`
 	ZoneID     uint                 `gorm:"primaryKey" json:"zone_id"`
 	Permission enums.UserPermission `json:"permission"`
+	// TrashID is the trash entry the grant went with, see Zone.TrashID.
+	TrashID *uint `gorm:"index" json:"-"`
 }
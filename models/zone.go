@@ -12,4 +12,6 @@ type Zone struct {
 	Level    int            `Gorm:"index"`
 	ParentID *uint          `Gorm:"foreignKey:ParentID; references:ID; index; default:NULL" json:"parent_id"`
 	Metadata datatypes.JSON `Gorm:"type:json"`
+	// TrashID is the trash entry the zone was deleted with.
+	TrashID *uint `gorm:"index" json:"-"`
 }
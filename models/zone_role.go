@@ -0,0 +1,12 @@
+package models
+
+import "golang-rest-user/enums"
+
+// ZoneRole is a tenant-defined zone role. The built-in owner, editor and
+// viewer roles are not stored.
+type ZoneRole struct {
+	BaseModel
+	Name        string             `gorm:"size:50;uniqueIndex;not null" json:"name"`
+	Description string             `gorm:"size:255" json:"description"`
+	Actions     []enums.ZoneAction `gorm:"type:json;serializer:json" json:"actions"`
+}
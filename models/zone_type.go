@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/datatypes"
+
+// ZoneType constrains the zones of one type: their metadata must satisfy
+// MetadataSchema, their parent must be of one of AllowedParents (any type if
+// empty) and they may not sit deeper than MaxDepth, the root being level 1.
+type ZoneType struct {
+	BaseModel
+	Name           string         `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description    string         `gorm:"size:255" json:"description"`
+	MetadataSchema datatypes.JSON `gorm:"type:json" json:"metadata_schema"`
+	AllowedParents []string       `gorm:"type:json;serializer:json" json:"allowed_parents"`
+	AllowRoot      bool           `gorm:"not null" json:"allow_root"`
+	MaxDepth       int            `gorm:"not null" json:"max_depth"`
+}
@@ -1,9 +1,10 @@
 package mySqlProvider
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"golang-rest-user/models"
+	"golang-rest-user/migration"
 	"log"
 	"os"
 
@@ -33,7 +34,7 @@ func CreateInstanceDB(dbUser, dbPass, dbHost, dbPort, dbName string) (instance *
 	return instance, err
 }
 
-func CreateDB(dbUser, dbPass, dbHost, dbPort, dbName string) error {
+func openServer(dbUser, dbPass, dbHost, dbPort string) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%s)/information_schema?charset=utf8mb4&parseTime=true",
 		dbUser,
@@ -43,19 +44,63 @@ func CreateDB(dbUser, dbPass, dbHost, dbPort, dbName string) error {
 	)
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
-		log.Printf("Error connect to DB %s", dsn)
+		log.Printf("Error connect to DB server %s:%s", dbHost, dbPort)
+		return nil, err
+	}
+	return db, nil
+}
+
+func CreateDB(dbUser, dbPass, dbHost, dbPort, dbName string) error {
+	db, err := openServer(dbUser, dbPass, dbHost, dbPort)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("CREATE DATABASE IF NOT EXISTS `" + dbName + "`")
+	if err != nil {
+		log.Printf("Error creating DB %s", dbName)
+	}
+	return err
+}
+
+func DatabaseExists(dbUser, dbPass, dbHost, dbPort, dbName string) (bool, error) {
+	db, err := openServer(dbUser, dbPass, dbHost, dbPort)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?", dbName).Scan(&count)
+	return count > 0, err
+}
+
+func DropDB(dbUser, dbPass, dbHost, dbPort, dbName string) error {
+	db, err := openServer(dbUser, dbPass, dbHost, dbPort)
+	if err != nil {
 		return err
 	}
 	defer db.Close()
 
-	_, err = db.Exec("CREATE DATABASE IF NOT EXISTS " + dbName)
+	_, err = db.Exec("DROP DATABASE IF EXISTS `" + dbName + "`")
 	if err != nil {
-		log.Printf("Error creating DB %s", dsn)
+		log.Printf("Error dropping DB %s", dbName)
 	}
-	return nil
+	return err
 }
 
 func Init() {
+	if err := Connect(); err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	if _, err := Migrate(context.Background()); err != nil {
+		log.Fatalf("failed to migrate master db: %v", err)
+	}
+}
+
+// Connect opens the master DB without touching its schema.
+func Connect() error {
 	var err error
 	dbHost := os.Getenv("DB_HOST")
 	dbPort := os.Getenv("DB_PORT")
@@ -66,11 +111,33 @@ func Init() {
 	_ = CreateDB(dbUser, dbPass, dbHost, dbPort, dbName)
 
 	if instance, err = CreateInstanceDB(dbUser, dbPass, dbHost, dbPort, dbName); err != nil {
-		log.Fatalf("failed to connect database: %v", err)
+		return err
+	}
+	sqlDB, err := instance.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
+
+func Migrator() (*migration.Migrator, error) {
+	sqlDB, err := instance.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migration.NewMigrator(sqlDB, migration.Master), nil
+}
+
+func Migrate(ctx context.Context) (*migration.Result, error) {
+	migrator, err := Migrator()
+	if err != nil {
+		return nil, err
 	}
-	if err = instance.AutoMigrate(&models.Tenant{}); err != nil {
-		log.Fatalf("failed to auto migrate tenant: %v", err)
+	result, err := migrator.Up(ctx)
+	if err == nil && len(result.Applied) > 0 {
+		log.Printf("master db migrated from %d to %d", result.From, result.To)
 	}
+	return result, err
 }
 
 func GetInstance() *gorm.DB {
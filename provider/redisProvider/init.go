@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -16,14 +16,17 @@ var (
 )
 
 func Init() {
+	if err := Connect(); err != nil {
+		panic("redis connection failed:" + err.Error())
+	}
+}
+
+func Connect() error {
 	client = redis.NewClient(&redis.Options{
 		Addr: os.Getenv("REDIS_ADDR"),
 		DB:   0,
 	})
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		panic("redis connection failed:" + err.Error())
-	}
+	return client.Ping(ctx).Err()
 }
 
 func GetClient() *redis.Client {
@@ -55,58 +58,17 @@ func userTokenVersion(tenant string, userID uint) string {
 	)
 }
 
-func Create(tokenHash string, userID uint, tenantCode string, ttl time.Duration) error {
+func RevokeAllByUser(tenantCode string, userID uint) error {
 
-	refreshKey := refreshKey(tenantCode, userID, tokenHash)
 	userSetKey := userRefreshSetKey(tenantCode, userID)
 
-	pipe := client.TxPipeline()
-
-	pipe.HSet(ctx, refreshKey, map[string]interface{}{
-		"user_id":     userID,
-		"tenant_code": tenantCode,
-	})
-
-	pipe.Expire(ctx, refreshKey, ttl)
-	pipe.SAdd(ctx, userSetKey, tokenHash)
-	pipe.Expire(ctx, userSetKey, ttl)
-
-	_, err := pipe.Exec(ctx)
-	return err
-}
-
-func FindValidByHash(tokenHash string, tenantCode string, userID uint) error {
-
-	key := refreshKey(tenantCode, userID, tokenHash)
-
-	exists, err := client.Exists(ctx, key).Result()
+	tokens, err := client.SMembers(ctx, userSetKey).Result()
 	if err != nil {
 		return err
 	}
-	if exists == 0 {
-		return errors.New("refresh token revoked or expired")
-	}
-	return nil
-}
-
-func Revoke(tokenHash string, tenantCode string, userID uint) error {
-
-	refreshKey := refreshKey(tenantCode, userID, tokenHash)
-	userSetKey := userRefreshSetKey(tenantCode, userID)
-
-	pipe := client.TxPipeline()
-	pipe.Del(ctx, refreshKey)
-	pipe.SRem(ctx, userSetKey, tokenHash)
-
-	_, err := pipe.Exec(ctx)
-	return err
-}
-
-func RevokeAllByUser(tenantCode string, userID uint) error {
-
-	userSetKey := userRefreshSetKey(tenantCode, userID)
 
-	tokens, err := client.SMembers(ctx, userSetKey).Result()
+	familiesKey := userFamiliesKey(tenantCode, userID)
+	families, err := client.SMembers(ctx, familiesKey).Result()
 	if err != nil {
 		return err
 	}
@@ -115,7 +77,10 @@ func RevokeAllByUser(tenantCode string, userID uint) error {
 	for _, token := range tokens {
 		pipe.Del(ctx, refreshKey(tenantCode, userID, token))
 	}
-	pipe.Del(ctx, userSetKey)
+	for _, familyID := range families {
+		pipe.Del(ctx, familyKey(tenantCode, userID, familyID), familyTokensKey(tenantCode, userID, familyID))
+	}
+	pipe.Del(ctx, userSetKey, familiesKey)
 
 	_, err = pipe.Exec(ctx)
 	return err
@@ -135,3 +100,41 @@ func IncreaseTokenVer(userID uint, tenantCode string) error {
 	key := userTokenVersion(tenantCode, userID)
 	return client.Incr(ctx, key).Err()
 }
+
+// RevokeAllByTenant deletes every refresh token of the tenant and bumps every
+// user's token version so outstanding access tokens stop working too.
+func RevokeAllByTenant(tenantCode string) error {
+	prefix := fmt.Sprintf("auth:{%s}:user:", escapePattern(tenantCode))
+
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, prefix+"*", 500).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			pipe := client.TxPipeline()
+			for _, key := range keys {
+				if strings.HasSuffix(key, ":token_ver") {
+					pipe.Incr(ctx, key)
+					continue
+				}
+				pipe.Del(ctx, key)
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func escapePattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(s)
+}
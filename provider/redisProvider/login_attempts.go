@@ -0,0 +1,133 @@
+package redisProvider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func loginUserKey(tenant, username, suffix string) string {
+	return fmt.Sprintf(
+		"auth:{%s}:login:user:%s:%s",
+		tenant,
+		strings.ToLower(username),
+		suffix,
+	)
+}
+
+func loginIPKey(tenant, ip string) string {
+	return fmt.Sprintf(
+		"auth:{%s}:login:ip:%s:failures",
+		tenant,
+		ip,
+	)
+}
+
+// addToWindow records one event in a sliding window sorted set and returns
+// how many events fall within the window.
+func addToWindow(pipe redis.Pipeliner, key string, window time.Duration) *redis.IntCmd {
+	now := time.Now()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprint(now.Add(-window).UnixMilli()))
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: uuid.New().String()})
+	pipe.Expire(ctx, key, window)
+	return pipe.ZCard(ctx, key)
+}
+
+func countWindow(pipe redis.Pipeliner, key string, window time.Duration) *redis.IntCmd {
+	min := fmt.Sprint(time.Now().Add(-window).UnixMilli())
+	return pipe.ZCount(ctx, key, min, "+inf")
+}
+
+// RecordLoginFailure counts a failed login for the username and the IP and
+// returns the failures of each within the window.
+func RecordLoginFailure(tenantCode, username, ip string, window time.Duration) (int64, int64, error) {
+	pipe := client.TxPipeline()
+	userCount := addToWindow(pipe, loginUserKey(tenantCode, username, "failures"), window)
+	ipCount := addToWindow(pipe, loginIPKey(tenantCode, ip), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return userCount.Val(), ipCount.Val(), nil
+}
+
+func CountLoginFailures(tenantCode, username, ip string, window time.Duration) (int64, int64, error) {
+	pipe := client.Pipeline()
+	userCount := countWindow(pipe, loginUserKey(tenantCode, username, "failures"), window)
+	ipCount := countWindow(pipe, loginIPKey(tenantCode, ip), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return userCount.Val(), ipCount.Val(), nil
+}
+
+// ClearLoginFailures forgets the username's failures after a successful
+// login. The IP counter is kept, one correct password must not reset it.
+func ClearLoginFailures(tenantCode, username string) error {
+	return client.Del(ctx,
+		loginUserKey(tenantCode, username, "failures"),
+		loginUserKey(tenantCode, username, "delay"),
+	).Err()
+}
+
+// DelayLogin makes the username wait d before its next attempt.
+func DelayLogin(tenantCode, username string, d time.Duration) error {
+	return client.Set(ctx, loginUserKey(tenantCode, username, "delay"), 1, d).Err()
+}
+
+// LoginDelay returns how long the username still has to wait, 0 if not.
+func LoginDelay(tenantCode, username string) (time.Duration, error) {
+	return remaining(loginUserKey(tenantCode, username, "delay"))
+}
+
+func LockAccount(tenantCode, username string, d time.Duration) error {
+	return client.Set(ctx, loginUserKey(tenantCode, username, "lock"), time.Now().Add(d).Unix(), d).Err()
+}
+
+// AccountLock returns how long the account stays locked, 0 if it is not.
+func AccountLock(tenantCode, username string) (time.Duration, error) {
+	return remaining(loginUserKey(tenantCode, username, "lock"))
+}
+
+// AccountLocks returns the remaining lock of each username, in one round trip.
+func AccountLocks(tenantCode string, usernames []string) ([]time.Duration, error) {
+	pipe := client.Pipeline()
+	cmds := make([]*redis.DurationCmd, len(usernames))
+	for i, username := range usernames {
+		cmds[i] = pipe.PTTL(ctx, loginUserKey(tenantCode, username, "lock"))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	result := make([]time.Duration, len(usernames))
+	for i, cmd := range cmds {
+		if d := cmd.Val(); d > 0 {
+			result[i] = d
+		}
+	}
+	return result, nil
+}
+
+// UnlockAccount lifts the lock and clears the failures and delay.
+func UnlockAccount(tenantCode, username string) error {
+	return client.Del(ctx,
+		loginUserKey(tenantCode, username, "lock"),
+		loginUserKey(tenantCode, username, "failures"),
+		loginUserKey(tenantCode, username, "delay"),
+	).Err()
+}
+
+func remaining(key string) (time.Duration, error) {
+	d, err := client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		// -2 missing, -1 no expiry (never set by us)
+		return 0, nil
+	}
+	return d, nil
+}
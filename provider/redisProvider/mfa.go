@@ -0,0 +1,96 @@
+package redisProvider
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MFAChallenge is a password-checked login waiting for its second factor.
+// Enroll is set when the tenant requires MFA and the user has none yet.
+type MFAChallenge struct {
+	UserID uint
+	Device string
+	Enroll bool
+}
+
+func mfaChallengeKey(tenant, tokenHash string) string {
+	return fmt.Sprintf(
+		"auth:{%s}:mfa_challenge:%s",
+		tenant,
+		tokenHash,
+	)
+}
+
+func totpStepKey(tenant string, userID uint, step int64) string {
+	return fmt.Sprintf(
+		"auth:{%s}:user:%d:totp_step:%d",
+		tenant,
+		userID,
+		step,
+	)
+}
+
+func CreateMFAChallenge(tenantCode, tokenHash string, challenge MFAChallenge, ttl time.Duration) error {
+	key := mfaChallengeKey(tenantCode, tokenHash)
+	pipe := client.TxPipeline()
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"user_id":  challenge.UserID,
+		"device":   challenge.Device,
+		"enroll":   challenge.Enroll,
+		"attempts": 0,
+	})
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func GetMFAChallenge(tenantCode, tokenHash string) (*MFAChallenge, error) {
+	values, err := client.HGetAll(ctx, mfaChallengeKey(tenantCode, tokenHash)).Result()
+	if err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseUint(values["user_id"], 10, 64)
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+	return &MFAChallenge{
+		UserID: uint(userID),
+		Device: values["device"],
+		Enroll: values["enroll"] == "1",
+	}, nil
+}
+
+// failMFAScript counts a wrong code only while the challenge exists, so an
+// expired challenge is not recreated without a TTL, and drops it once it had
+// ARGV[1] attempts.
+var failMFAScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if redis.call('HINCRBY', KEYS[1], 'attempts', 1) >= tonumber(ARGV[1]) then
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`)
+
+// FailMFAChallenge counts a wrong code and drops the challenge once it had
+// maxAttempts, so the password has to be entered again.
+func FailMFAChallenge(tenantCode, tokenHash string, maxAttempts int64) error {
+	return failMFAScript.Run(ctx, client, []string{mfaChallengeKey(tenantCode, tokenHash)}, maxAttempts).Err()
+}
+
+// DeleteMFAChallenge ends the challenge and reports whether it still existed,
+// so concurrent requests cannot both complete it.
+func DeleteMFAChallenge(tenantCode, tokenHash string) (bool, error) {
+	n, err := client.Del(ctx, mfaChallengeKey(tenantCode, tokenHash)).Result()
+	return n == 1, err
+}
+
+// UseTOTPStep marks the user's code of step as used and reports false if it
+// already was, so a code cannot be replayed within its validity.
+func UseTOTPStep(tenantCode string, userID uint, step int64, ttl time.Duration) (bool, error) {
+	return client.SetNX(ctx, totpStepKey(tenantCode, userID, step), 1, ttl).Result()
+}
@@ -0,0 +1,49 @@
+package redisProvider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailMFAChallenge(t *testing.T) {
+	tests := []struct {
+		name        string
+		create      bool
+		failures    int
+		wantExists  bool
+		wantAttempt string
+	}{
+		{name: "counts a failure", create: true, failures: 1, wantExists: true, wantAttempt: "1"},
+		{name: "drops the challenge at the limit", create: true, failures: 3},
+		{name: "does not recreate an expired challenge", failures: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := useMiniredis(t)
+			if tt.create {
+				if err := CreateMFAChallenge(testTenant, "c1", MFAChallenge{UserID: 1}, time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 0; i < tt.failures; i++ {
+				if err := FailMFAChallenge(testTenant, "c1", 3); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			key := mfaChallengeKey(testTenant, "c1")
+			if got := server.Exists(key); got != tt.wantExists {
+				t.Fatalf("exists = %v, want %v", got, tt.wantExists)
+			}
+			if !tt.wantExists {
+				return
+			}
+			if got := server.HGet(key, "attempts"); got != tt.wantAttempt {
+				t.Fatalf("attempts = %q, want %q", got, tt.wantAttempt)
+			}
+			if server.TTL(key) <= 0 {
+				t.Fatal("challenge lost its TTL")
+			}
+		})
+	}
+}
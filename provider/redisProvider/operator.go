@@ -0,0 +1,105 @@
+package redisProvider
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func operatorRefreshKey(operatorID uint, tokenHash string) string {
+	return fmt.Sprintf(
+		"auth:{platform}:operator:%d:refresh:%s",
+		operatorID,
+		tokenHash,
+	)
+}
+
+func operatorRefreshSetKey(operatorID uint) string {
+	return fmt.Sprintf(
+		"auth:{platform}:operator:%d:refresh_tokens",
+		operatorID,
+	)
+}
+
+func operatorTokenVersion(operatorID uint) string {
+	return fmt.Sprintf(
+		"auth:{platform}:operator:%d:token_ver",
+		operatorID,
+	)
+}
+
+func CreateOperatorRefresh(tokenHash string, operatorID uint, ttl time.Duration) error {
+
+	refreshKey := operatorRefreshKey(operatorID, tokenHash)
+	setKey := operatorRefreshSetKey(operatorID)
+
+	pipe := client.TxPipeline()
+
+	pipe.HSet(ctx, refreshKey, map[string]interface{}{
+		"operator_id": operatorID,
+	})
+
+	pipe.Expire(ctx, refreshKey, ttl)
+	pipe.SAdd(ctx, setKey, tokenHash)
+	pipe.Expire(ctx, setKey, ttl)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func FindValidOperatorRefresh(tokenHash string, operatorID uint) error {
+
+	exists, err := client.Exists(ctx, operatorRefreshKey(operatorID, tokenHash)).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return errors.New("refresh token revoked or expired")
+	}
+	return nil
+}
+
+func RevokeOperatorRefresh(tokenHash string, operatorID uint) error {
+
+	pipe := client.TxPipeline()
+	pipe.Del(ctx, operatorRefreshKey(operatorID, tokenHash))
+	pipe.SRem(ctx, operatorRefreshSetKey(operatorID), tokenHash)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func RevokeAllByOperator(operatorID uint) error {
+
+	setKey := operatorRefreshSetKey(operatorID)
+
+	tokens, err := client.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := client.TxPipeline()
+	for _, token := range tokens {
+		pipe.Del(ctx, operatorRefreshKey(operatorID, token))
+	}
+	pipe.Del(ctx, setKey)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func GetOperatorTokenVer(operatorID uint) int {
+	key := operatorTokenVersion(operatorID)
+	val, err := client.Get(ctx, key).Int()
+	if errors.Is(err, redis.Nil) {
+		client.Set(ctx, key, 1, 0)
+		return 1
+	}
+	return val
+}
+
+func IncreaseOperatorTokenVer(operatorID uint) error {
+	return client.Incr(ctx, operatorTokenVersion(operatorID)).Err()
+}
@@ -0,0 +1,270 @@
+package redisProvider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrRefreshRevoked = errors.New("refresh token revoked or expired")
+	ErrRefreshReused  = errors.New("refresh token reused")
+)
+
+// A token family is the chain of refresh tokens that starts at one login.
+// Rotated tokens are kept (marked by their use count) until they expire, so
+// presenting one again is detected as reuse.
+func familyKey(tenant string, userID uint, familyID string) string {
+	return fmt.Sprintf(
+		"auth:{%s}:user:%d:family:%s",
+		tenant,
+		userID,
+		familyID,
+	)
+}
+
+func familyTokensKey(tenant string, userID uint, familyID string) string {
+	return familyKey(tenant, userID, familyID) + ":tokens"
+}
+
+func userFamiliesKey(tenant string, userID uint) string {
+	return fmt.Sprintf(
+		"auth:{%s}:user:%d:families",
+		tenant,
+		userID,
+	)
+}
+
+// SessionMeta describes the client a token family was issued to.
+type SessionMeta struct {
+	Device    string
+	UserAgent string
+	IP        string
+}
+
+// Session is a token family as seen by its user.
+type Session struct {
+	ID         string
+	Device     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// CreateFamily starts a new token family (a session) and returns its ID.
+func CreateFamily(tenantCode string, userID uint, ttl time.Duration, meta SessionMeta) (string, error) {
+	familyID := uuid.New().String()
+	key := familyKey(tenantCode, userID, familyID)
+	setKey := userFamiliesKey(tenantCode, userID)
+	now := time.Now().Unix()
+
+	pipe := client.TxPipeline()
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"user_id":      userID,
+		"device":       meta.Device,
+		"user_agent":   meta.UserAgent,
+		"ip":           meta.IP,
+		"created_at":   now,
+		"last_used_at": now,
+	})
+	pipe.Expire(ctx, key, ttl)
+	pipe.SAdd(ctx, setKey, familyID)
+	pipe.Expire(ctx, setKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return familyID, err
+}
+
+// touchScript updates the session only while the family still exists, so a
+// family revoked since the rotation is not recreated without a TTL.
+var touchScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'user_agent', ARGV[1], 'ip', ARGV[2], 'last_used_at', ARGV[3])
+return 1
+`)
+
+// TouchFamily records a refresh of the session. The device name given at
+// login is kept. It returns ErrRefreshRevoked if the family is gone.
+func TouchFamily(tenantCode string, userID uint, familyID string, meta SessionMeta) error {
+	key := familyKey(tenantCode, userID, familyID)
+	touched, err := touchScript.Run(ctx, client, []string{key}, meta.UserAgent, meta.IP, time.Now().Unix()).Int()
+	if err != nil {
+		return err
+	}
+	if touched == 0 {
+		return ErrRefreshRevoked
+	}
+	return nil
+}
+
+func FamilyExists(tenantCode string, userID uint, familyID string) (bool, error) {
+	n, err := client.Exists(ctx, familyKey(tenantCode, userID, familyID)).Result()
+	return n > 0, err
+}
+
+// FamilyOf returns the family of a refresh token, empty for tokens issued
+// before families existed.
+func FamilyOf(tokenHash string, tenantCode string, userID uint) (string, error) {
+	familyID, err := client.HGet(ctx, refreshKey(tenantCode, userID, tokenHash), "family").Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return familyID, err
+}
+
+// ListFamilies returns the user's live sessions and forgets expired ones.
+func ListFamilies(tenantCode string, userID uint) ([]Session, error) {
+	setKey := userFamiliesKey(tenantCode, userID)
+	ids, err := client.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, familyKey(tenantCode, userID, id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(ids))
+	for i, id := range ids {
+		values := cmds[i].Val()
+		if len(values) == 0 {
+			client.SRem(ctx, setKey, id)
+			continue
+		}
+		sessions = append(sessions, Session{
+			ID:         id,
+			Device:     values["device"],
+			UserAgent:  values["user_agent"],
+			IP:         values["ip"],
+			CreatedAt:  parseUnix(values["created_at"]),
+			LastUsedAt: parseUnix(values["last_used_at"]),
+		})
+	}
+	return sessions, nil
+}
+
+func parseUnix(value string) time.Time {
+	sec, _ := strconv.ParseInt(value, 10, 64)
+	return time.Unix(sec, 0)
+}
+
+// Create stores a refresh token as the newest member of its family. parent
+// is the hash of the token it replaces, empty for the first token.
+func Create(tokenHash string, userID uint, tenantCode, familyID, parent string, ttl time.Duration) error {
+	refreshKey := refreshKey(tenantCode, userID, tokenHash)
+	userSetKey := userRefreshSetKey(tenantCode, userID)
+	famKey := familyKey(tenantCode, userID, familyID)
+	famTokensKey := familyTokensKey(tenantCode, userID, familyID)
+
+	pipe := client.TxPipeline()
+	pipe.HSet(ctx, refreshKey, map[string]interface{}{
+		"user_id":     userID,
+		"tenant_code": tenantCode,
+		"family":      familyID,
+		"parent":      parent,
+		"uses":        0,
+	})
+	pipe.Expire(ctx, refreshKey, ttl)
+	pipe.SAdd(ctx, userSetKey, tokenHash)
+	pipe.Expire(ctx, userSetKey, ttl)
+	pipe.SAdd(ctx, famTokensKey, tokenHash)
+	// the family lives as long as its newest token
+	pipe.Expire(ctx, famTokensKey, ttl)
+	pipe.Expire(ctx, famKey, ttl)
+	pipe.Expire(ctx, userFamiliesKey(tenantCode, userID), ttl)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// rotateScript counts a use of the token, atomically with checking it still
+// exists, so a token deleted meanwhile is not recreated without a TTL. It
+// returns nil for a missing token, else the use count and the family, empty
+// for tokens issued before families existed.
+var rotateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local uses = redis.call('HINCRBY', KEYS[1], 'uses', 1)
+local family = redis.call('HGET', KEYS[1], 'family') or ''
+return {uses, family}
+`)
+
+// Rotate marks the refresh token as used and returns its family. The first
+// call wins; any later call with the same token returns ErrRefreshReused
+// together with the family so the caller can revoke it.
+func Rotate(tokenHash string, tenantCode string, userID uint) (string, error) {
+	key := refreshKey(tenantCode, userID, tokenHash)
+
+	result, err := rotateScript.Run(ctx, client, []string{key}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrRefreshRevoked
+	}
+	if err != nil {
+		return "", err
+	}
+	uses, _ := result[0].(int64)
+	familyID, _ := result[1].(string)
+
+	if familyID == "" {
+		// issued before families existed: usable once, the caller starts a
+		// family for its successor
+		if uses > 1 {
+			return "", ErrRefreshRevoked
+		}
+		return "", nil
+	}
+	if uses > 1 {
+		return familyID, ErrRefreshReused
+	}
+
+	exists, err := client.Exists(ctx, familyKey(tenantCode, userID, familyID)).Result()
+	if err != nil {
+		return "", err
+	}
+	if exists == 0 {
+		return "", ErrRefreshRevoked
+	}
+	return familyID, nil
+}
+
+// RevokeFamily deletes every token of the family, rotated or not.
+func RevokeFamily(tenantCode string, userID uint, familyID string) error {
+	famTokensKey := familyTokensKey(tenantCode, userID, familyID)
+
+	tokens, err := client.SMembers(ctx, famTokensKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := client.TxPipeline()
+	for _, token := range tokens {
+		pipe.Del(ctx, refreshKey(tenantCode, userID, token))
+		pipe.SRem(ctx, userRefreshSetKey(tenantCode, userID), token)
+	}
+	pipe.Del(ctx, famTokensKey, familyKey(tenantCode, userID, familyID))
+	pipe.SRem(ctx, userFamiliesKey(tenantCode, userID), familyID)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Revoke deletes a single refresh token that does not belong to a family.
+func Revoke(tokenHash string, tenantCode string, userID uint) error {
+	pipe := client.TxPipeline()
+	pipe.Del(ctx, refreshKey(tenantCode, userID, tokenHash))
+	pipe.SRem(ctx, userRefreshSetKey(tenantCode, userID), tokenHash)
+	_, err := pipe.Exec(ctx)
+	return err
+}
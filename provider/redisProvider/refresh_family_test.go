@@ -0,0 +1,153 @@
+package redisProvider
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testTenant = "acme"
+
+func newFamilyToken(t *testing.T, userID uint, tokenHash string) string {
+	t.Helper()
+	familyID, err := CreateFamily(testTenant, userID, time.Hour, SessionMeta{Device: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Create(tokenHash, userID, testTenant, familyID, "", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	return familyID
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T) (tokenHash, familyID string)
+		// rotations before the one checked
+		before     int
+		wantErr    error
+		wantFamily bool
+	}{
+		{
+			name: "first use",
+			prepare: func(t *testing.T) (string, string) {
+				return "t1", newFamilyToken(t, 1, "t1")
+			},
+			wantFamily: true,
+		},
+		{
+			name: "reuse returns the family to revoke",
+			prepare: func(t *testing.T) (string, string) {
+				return "t1", newFamilyToken(t, 1, "t1")
+			},
+			before:     1,
+			wantErr:    ErrRefreshReused,
+			wantFamily: true,
+		},
+		{
+			name: "unknown token",
+			prepare: func(t *testing.T) (string, string) {
+				return "missing", ""
+			},
+			wantErr: ErrRefreshRevoked,
+		},
+		{
+			name: "revoked family",
+			prepare: func(t *testing.T) (string, string) {
+				familyID := newFamilyToken(t, 1, "t1")
+				if err := RevokeFamily(testTenant, 1, familyID); err != nil {
+					t.Fatal(err)
+				}
+				return "t1", ""
+			},
+			wantErr: ErrRefreshRevoked,
+		},
+		{
+			name: "expired family",
+			prepare: func(t *testing.T) (string, string) {
+				familyID := newFamilyToken(t, 1, "t1")
+				client.Del(ctx, familyKey(testTenant, 1, familyID))
+				return "t1", ""
+			},
+			wantErr: ErrRefreshRevoked,
+		},
+		{
+			name: "token without family is usable once",
+			prepare: func(t *testing.T) (string, string) {
+				client.HSet(ctx, refreshKey(testTenant, 1, "legacy"), "user_id", 1, "uses", 0)
+				return "legacy", ""
+			},
+		},
+		{
+			name: "token without family reused",
+			prepare: func(t *testing.T) (string, string) {
+				client.HSet(ctx, refreshKey(testTenant, 1, "legacy"), "user_id", 1, "uses", 0)
+				return "legacy", ""
+			},
+			before:  1,
+			wantErr: ErrRefreshRevoked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useMiniredis(t)
+			tokenHash, familyID := tt.prepare(t)
+			for i := 0; i < tt.before; i++ {
+				_, _ = Rotate(tokenHash, testTenant, 1)
+			}
+
+			got, err := Rotate(tokenHash, testTenant, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantFamily && got != familyID {
+				t.Fatalf("family = %q, want %q", got, familyID)
+			}
+			if !tt.wantFamily && got != "" {
+				t.Fatalf("family = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestRotateDoesNotRecreateDeletedToken(t *testing.T) {
+	server := useMiniredis(t)
+	familyID := newFamilyToken(t, 1, "t1")
+	if err := RevokeFamily(testTenant, 1, familyID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Rotate("t1", testTenant, 1); !errors.Is(err, ErrRefreshRevoked) {
+		t.Fatalf("err = %v, want ErrRefreshRevoked", err)
+	}
+	if server.Exists(refreshKey(testTenant, 1, "t1")) {
+		t.Fatal("rotating a revoked token recreated its key")
+	}
+}
+
+func TestTouchFamily(t *testing.T) {
+	server := useMiniredis(t)
+	familyID := newFamilyToken(t, 1, "t1")
+
+	if err := TouchFamily(testTenant, 1, familyID, SessionMeta{UserAgent: "agent", IP: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	key := familyKey(testTenant, 1, familyID)
+	if got := server.HGet(key, "ip"); got != "10.0.0.1" {
+		t.Fatalf("ip = %q, want 10.0.0.1", got)
+	}
+	if got := server.HGet(key, "device"); got != "test" {
+		t.Fatalf("device = %q, want it kept", got)
+	}
+
+	if err := RevokeFamily(testTenant, 1, familyID); err != nil {
+		t.Fatal(err)
+	}
+	if err := TouchFamily(testTenant, 1, familyID, SessionMeta{}); !errors.Is(err, ErrRefreshRevoked) {
+		t.Fatalf("err = %v, want ErrRefreshRevoked", err)
+	}
+	if server.Exists(key) {
+		t.Fatal("touching a revoked family recreated it")
+	}
+}
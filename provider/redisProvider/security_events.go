@@ -0,0 +1,16 @@
+package redisProvider
+
+import "github.com/redis/go-redis/v9"
+
+const securityEventStream = "security:events"
+
+// AddSecurityEvent appends an event to the security stream, which is capped
+// to roughly the last 100k events.
+func AddSecurityEvent(values map[string]interface{}) error {
+	return client.XAdd(ctx, &redis.XAddArgs{
+		Stream: securityEventStream,
+		MaxLen: 100000,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
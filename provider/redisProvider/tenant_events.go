@@ -0,0 +1,19 @@
+package redisProvider
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const tenantEventChannel = "tenant:events"
+
+func PublishTenantEvent(payload []byte) error {
+	return client.Publish(ctx, tenantEventChannel, payload).Err()
+}
+
+// SubscribeTenantEvents returns a subscription to tenant changes made by
+// other processes. The caller closes it.
+func SubscribeTenantEvents(c context.Context) *redis.PubSub {
+	return client.Subscribe(c, tenantEventChannel)
+}
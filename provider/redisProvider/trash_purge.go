@@ -0,0 +1,34 @@
+package redisProvider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func trashPurgeLockKey(tenant string) string {
+	return fmt.Sprintf(
+		"zones:{%s}:trash_purge_lock",
+		tenant,
+	)
+}
+
+// unlockScript deletes the lock only while it still holds the caller's token,
+// so a purge that outlived its lock cannot free the next holder's.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LockTrashPurge claims the tenant's trash purge for ttl, so that only one
+// API instance runs it at a time. ok is false while another one holds it.
+func LockTrashPurge(tenantCode, token string, ttl time.Duration) (ok bool, err error) {
+	return client.SetNX(ctx, trashPurgeLockKey(tenantCode), token, ttl).Result()
+}
+
+func UnlockTrashPurge(tenantCode, token string) error {
+	return unlockScript.Run(ctx, client, []string{trashPurgeLockKey(tenantCode)}, token).Err()
+}
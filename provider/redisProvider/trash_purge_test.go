@@ -0,0 +1,38 @@
+package redisProvider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockTrashPurge(t *testing.T) {
+	server := useMiniredis(t)
+
+	if ok, err := LockTrashPurge("acme", "first", time.Minute); err != nil || !ok {
+		t.Fatalf("first lock = %v, %v, want it taken", ok, err)
+	}
+	if ok, err := LockTrashPurge("acme", "second", time.Minute); err != nil || ok {
+		t.Fatalf("second lock = %v, %v, want it refused", ok, err)
+	}
+	if ok, err := LockTrashPurge("other", "second", time.Minute); err != nil || !ok {
+		t.Fatalf("lock of another tenant = %v, %v, want it taken", ok, err)
+	}
+
+	if err := UnlockTrashPurge("acme", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if !server.Exists(trashPurgeLockKey("acme")) {
+		t.Fatal("unlock with another token released the lock")
+	}
+	if err := UnlockTrashPurge("acme", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := LockTrashPurge("acme", "second", time.Minute); err != nil || !ok {
+		t.Fatalf("lock after unlock = %v, %v, want it taken", ok, err)
+	}
+
+	server.FastForward(time.Minute)
+	if ok, err := LockTrashPurge("acme", "third", time.Minute); err != nil || !ok {
+		t.Fatalf("lock after expiry = %v, %v, want it taken", ok, err)
+	}
+}
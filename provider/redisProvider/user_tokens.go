@@ -0,0 +1,94 @@
+package redisProvider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Single-use tokens mailed to a user, e.g. to reset a password. Only the hash
+// is stored and a user has at most one valid token per purpose.
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify"
+)
+
+var ErrTokenInvalid = errors.New("token is invalid or expired")
+
+func userTokenKey(tenant, purpose, tokenHash string) string {
+	return fmt.Sprintf(
+		"auth:{%s}:%s:%s",
+		tenant,
+		purpose,
+		tokenHash,
+	)
+}
+
+// userCurrentTokenKey holds the hash of the user's only valid token.
+func userCurrentTokenKey(tenant, purpose string, userID uint) string {
+	return fmt.Sprintf(
+		"auth:{%s}:user:%d:%s",
+		tenant,
+		userID,
+		purpose,
+	)
+}
+
+func userTokenRequestsKey(tenant, purpose, username string) string {
+	return fmt.Sprintf(
+		"auth:{%s}:%s_requests:%s",
+		tenant,
+		purpose,
+		strings.ToLower(username),
+	)
+}
+
+// CreateUserToken stores a token for the user, replacing the one issued
+// before so only the latest mail works.
+func CreateUserToken(tenantCode, purpose string, userID uint, tokenHash string, ttl time.Duration) error {
+	previous, err := client.SetArgs(ctx, userCurrentTokenKey(tenantCode, purpose, userID), tokenHash, redis.SetArgs{TTL: ttl, Get: true}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	pipe := client.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, userTokenKey(tenantCode, purpose, previous))
+	}
+	pipe.Set(ctx, userTokenKey(tenantCode, purpose, tokenHash), userID, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ConsumeUserToken deletes the token and returns its user. A token can only
+// be consumed once.
+func ConsumeUserToken(tenantCode, purpose, tokenHash string) (uint, error) {
+	val, err := client.GetDel(ctx, userTokenKey(tenantCode, purpose, tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	userID, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, ErrTokenInvalid
+	}
+	client.Del(ctx, userCurrentTokenKey(tenantCode, purpose, uint(userID)))
+	return uint(userID), nil
+}
+
+// RecordUserTokenRequest counts a request for a token mail to the username
+// and returns the requests within the window.
+func RecordUserTokenRequest(tenantCode, purpose, username string, window time.Duration) (int64, error) {
+	pipe := client.TxPipeline()
+	count := addToWindow(pipe, userTokenRequestsKey(tenantCode, purpose, username), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
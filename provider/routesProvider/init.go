@@ -1,9 +1,12 @@
 package routesProvider
 
 import (
+	"golang-rest-user/config"
+	"golang-rest-user/handler"
 	"golang-rest-user/middleware"
 	"golang-rest-user/provider/serviceProvider"
 	"golang-rest-user/routes"
+	"golang-rest-user/security"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,24 +18,65 @@ func Init(router *gin.Engine) {
 
 	router.Use(middleware.RequestID())
 
+	router.GET("/.well-known/jwks.json", handler.JWKS)
+
 	v1 := router.Group("api/v1")
 
+	platformAuth := v1.Group("/platform/auth")
+	routes.PlatformAuthRoutes(platformAuth)
+
+	operators := v1.Group("/platform/operators")
+	operators.Use(middleware.OperatorAuthMiddleware(jwtManager))
+	routes.OperatorRoutes(operators)
+
 	tenants := v1.Group("/tenants")
+	tenants.Use(middleware.OperatorAuthMiddleware(jwtManager))
 	routes.TenantRoutes(tenants)
 
+	resolverConfig := config.LoadTenantResolverConfig()
+	resolveTenant := middleware.ResolveTenant(middleware.NewTenantResolvers(resolverConfig)...)
+
+	initTenantRoutes(v1, jwtManager, resolveTenant)
+	if resolverConfig.Has(config.TenantResolverPath) {
+		// /t/acme/api/v1/... serves the same tenant routes for tenant acme
+		initTenantRoutes(router.Group("/t/:tenant/api/v1"), jwtManager, resolveTenant)
+	}
+}
+
+func initTenantRoutes(v1 *gin.RouterGroup, jwtManager *security.Manager, resolveTenant gin.HandlerFunc) {
 	auth := v1.Group("/auth")
-	auth.Use(middleware.TenantDBMiddleware())
+	auth.Use(resolveTenant)
 	routes.AuthRoutes(auth)
 
+	sessions := v1.Group("/sessions")
+	sessions.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
+	routes.SessionRoutes(sessions)
+
+	me := v1.Group("/me")
+	me.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
+	routes.MeRoutes(me)
+
+	mfa := v1.Group("/mfa")
+	mfa.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
+	routes.MFARoutes(mfa)
+
 	users := v1.Group("/users")
-	users.Use(middleware.AuthMiddleware(jwtManager))
+	users.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
 	routes.UserRoutes(users)
 
 	zones := v1.Group("/zones")
-	zones.Use(middleware.AuthMiddleware(jwtManager))
+	zones.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
 	routes.ZonesRoutes(zones)
 
+	zoneRoles := v1.Group("/zone-roles")
+	zoneRoles.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
+	routes.ZoneRoleRoutes(zoneRoles)
+
+	zoneTypes := v1.Group("/zone-types")
+	zoneTypes.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
+	routes.ZoneTypeRoutes(zoneTypes)
+
 	share := v1.Group("/zones/:uuid/share")
-	share.Use(middleware.AuthMiddleware(jwtManager))
+	share.Use(middleware.AuthMiddleware(jwtManager), resolveTenant)
 	routes.ShareRoutes(share)
 }
@@ -1,15 +1,28 @@
 package serviceProvider
 
 import (
+	"golang-rest-user/mailer"
 	"golang-rest-user/provider/mySqlProvider"
 	"golang-rest-user/repository"
 	"golang-rest-user/security"
 	"golang-rest-user/service"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 )
 
 type AppService struct {
-	TenantService service.TenantService
-	JWTManager    *security.Manager
+	TenantService       service.TenantService
+	ProvisioningService service.ProvisioningService
+	OperatorService     service.OperatorService
+	SigningKeyService   service.SigningKeyService
+	JWTManager          *security.Manager
+	Passwords           *security.PasswordManager
+	Mailer              mailer.Mailer
+	// PublicURL is the base URL of the app that links in mails point to.
+	PublicURL string
 }
 
 var instance *AppService
@@ -19,10 +32,48 @@ func Init() {
 	masterDB := mySqlProvider.GetInstance()
 
 	tenantRepo := repository.NewTenantRepo(masterDB)
-	instance.TenantService = service.NewTenantService(tenantRepo)
+	provisioningRepo := repository.NewProvisioningRepo(masterDB)
+	instance.ProvisioningService = service.NewProvisioningService(provisioningRepo, tenantRepo)
+	instance.TenantService = service.NewTenantService(tenantRepo, instance.ProvisioningService)
 
 	jwtConfig := security.LoadJWTConfig()
-	instance.JWTManager = security.NewManager(jwtConfig)
+	signingKeyRepo := repository.NewSigningKeyRepo(masterDB)
+	instance.SigningKeyService = service.NewSigningKeyService(signingKeyRepo, jwtConfig.RefreshTokenTTL)
+	if jwtConfig.Algorithm != security.AlgorithmHS256 && !security.IsAsymmetric(jwtConfig.Algorithm) {
+		log.Fatalf("unsupported JWT_SIGNING_ALGORITHM %q", jwtConfig.Algorithm)
+	}
+	if security.IsAsymmetric(jwtConfig.Algorithm) {
+		if err := instance.SigningKeyService.EnsureActive(jwtConfig.Algorithm); err != nil {
+			log.Fatalf("failed to prepare %s signing key: %v", jwtConfig.Algorithm, err)
+		}
+	}
+	instance.JWTManager = security.NewManager(jwtConfig, instance.SigningKeyService)
+	if err := instance.JWTManager.Reload(); err != nil {
+		log.Println("load signing keys:", err)
+	}
+	instance.Passwords = security.NewPasswordManagerFromConfig(security.LoadPasswordConfig())
+
+	mailConfig := mailer.LoadConfig()
+	if mailConfig.Driver == "" {
+		// stdout would put reset and verification tokens in the logs
+		if gin.Mode() == gin.ReleaseMode {
+			log.Fatal("mailer: MAIL_DRIVER must be set in release mode")
+		}
+		log.Println("WARNING: MAIL_DRIVER is not set, mail including reset and verification links is printed to stdout")
+		mailConfig.Driver = mailer.DriverStdout
+	}
+	mail, err := mailer.New(mailConfig)
+	if err != nil {
+		log.Fatalf("mailer: %v", err)
+	}
+	instance.Mailer = mail
+	instance.PublicURL = strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/")
+
+	operatorRepo := repository.NewOperatorRepo(masterDB)
+	instance.OperatorService = service.NewOperatorService(operatorRepo, instance.JWTManager, instance.Passwords)
+	if err := instance.OperatorService.EnsureBootstrapAdmin(os.Getenv("PLATFORM_ADMIN_USERNAME"), os.Getenv("PLATFORM_ADMIN_PASSWORD")); err != nil {
+		log.Println("bootstrap platform admin:", err)
+	}
 }
 
 func GetInstance() *AppService {
@@ -0,0 +1,81 @@
+package tenantProvider
+
+import (
+	"context"
+	"encoding/json"
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/provider/serviceProvider"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Every process keeps its own registry, so tenant changes are broadcast over
+// Redis and applied by the other API instances and CLI runs.
+type tenantEvent struct {
+	Origin string             `json:"origin"`
+	Mode   enums.HandleTenant `json:"mode"`
+	Code   string             `json:"code"`
+}
+
+var origin = uuid.New().String()
+
+func publish(mode enums.HandleTenant, tenantCode string) {
+	if redisProvider.GetClient() == nil {
+		return
+	}
+	payload, err := json.Marshal(tenantEvent{Origin: origin, Mode: mode, Code: tenantCode})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := redisProvider.PublishTenantEvent(payload); err != nil {
+		log.Printf("publish tenant %s event: %v", tenantCode, err)
+	}
+}
+
+// Subscribe applies tenant changes published by other processes until ctx
+// is done.
+func Subscribe(ctx context.Context) {
+	sub := redisProvider.SubscribeTenantEvents(ctx)
+	go func() {
+		defer sub.Close()
+		for msg := range sub.Channel() {
+			var event tenantEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Printf("invalid tenant event: %v", err)
+				continue
+			}
+			if event.Origin == origin {
+				continue
+			}
+			applyEvent(event)
+		}
+	}()
+}
+
+func applyEvent(event tenantEvent) {
+	switch event.Mode {
+	case enums.DeleteTenantConnect, enums.DropTenantConnect:
+		// only the origin drops the database
+		registry.Remove(event.Code, false)
+		return
+	}
+
+	tenant, err := serviceProvider.GetInstance().TenantService.GetTenantConnect(event.Code)
+	if err != nil {
+		log.Printf("tenant %s event: %v", event.Code, err)
+		return
+	}
+	if event.Mode == enums.SettingsTenantConnect {
+		registry.UpdateSettings(event.Code, tenant.Settings)
+		return
+	}
+	switch {
+	case tenant.Status == enums.TenantStatusInactive:
+		registry.Suspend(tenant)
+	case tenant.Status == enums.TenantStatusActive:
+		_ = registry.Provision(tenant, nil)
+	}
+}
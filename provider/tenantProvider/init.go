@@ -2,76 +2,111 @@ package tenantProvider
 
 import (
 	"fmt"
+	"golang-rest-user/dto"
 	"golang-rest-user/enums"
 	"golang-rest-user/models"
 	"golang-rest-user/provider/serviceProvider"
+	"log"
+	"sync"
 )
 
-var instance map[string]*TenantInfo
+var registry = NewRegistry()
 
 func Init() {
 	service := serviceProvider.GetInstance()
-	instance = make(map[string]*TenantInfo)
 
-	service.TenantService.SetCallBackFunction(HandleTenant)
+	Bind()
+	if err := service.ProvisioningService.FailInterrupted(); err != nil {
+		log.Println(err)
+	}
 	data, err := service.TenantService.ListAllTenantConnect()
-	if err == nil {
-		for _, item := range data {
-			temp := &TenantInfo{
-				Info: &item,
-			}
-			_ = temp.Init()
-			instance[item.Code] = temp
+	if err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range data {
+		tenant := &data[i]
+		switch tenant.Status {
+		case enums.TenantStatusInactive:
+			registry.Suspend(tenant)
+			continue
+		case enums.TenantStatusProvisioning, enums.TenantStatusFailed:
+			registry.MarkFailed(tenant, "provisioning did not complete")
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = registry.Provision(tenant, nil)
+		}()
 	}
+	wg.Wait()
 }
 
-func GetTenantInfo(tenantCode string) *TenantInfo {
-	return instance[tenantCode]
+// Bind routes tenant changes and provisioning jobs of the services into this
+// process's registry, without loading any tenant. One-off commands use it
+// directly instead of Init.
+func Bind() {
+	service := serviceProvider.GetInstance()
+	service.TenantService.SetCallBackFunction(HandleTenant)
+	service.ProvisioningService.SetProvisionFunction(provisionTenant)
 }
 
-func AddInstance(tenant *models.Tenant) {
-	temp := &TenantInfo{
-		Info: tenant,
-	}
-	_ = temp.Init()
-	instance[tenant.Code] = temp
+// Acquire returns the tenant's services and a release func that must be
+// called when the request is done with them.
+func Acquire(tenantCode string) (*TenantInfo, func(), error) {
+	return registry.Acquire(tenantCode)
 }
 
-func DeleteInstance(tenantCode string) {
-	temp := instance[tenantCode]
-	temp.Destruction()
-	instance[tenantCode] = nil
-	delete(instance, tenantCode)
+// GetTenantInfo returns a ready tenant without holding a reference on it.
+func GetTenantInfo(tenantCode string) *TenantInfo {
+	return registry.Get(tenantCode)
 }
 
-func EditInstance(tenant *models.Tenant) {
-	DeleteInstance(tenant.Code)
-	AddInstance(tenant)
+func GetTenantState(tenantCode string) (*dto.TenantStateResponse, error) {
+	return registry.State(tenantCode)
 }
 
-func DropInstance(tenantCode string) {
-	temp := instance[tenantCode]
-	temp.Drop()
-	instance[tenantCode] = nil
-	delete(instance, tenantCode)
+func ListTenantCodes() []string {
+	return registry.Codes()
 }
 
 func HandleTenant(mode enums.HandleTenant, tenantCode string, tenant *models.Tenant) {
+	// the caller keeps using its copy, the registry must own its own
+	if tenant != nil {
+		owned := *tenant
+		tenant = &owned
+	}
 	switch mode {
-	case enums.AddTenantConnect:
-		AddInstance(tenant)
-		break
-	case enums.EditTenantConnect:
-		EditInstance(tenant)
-		break
+	case enums.AddTenantConnect, enums.EditTenantConnect, enums.ResumeTenantConnect:
+		if tenant.Status == enums.TenantStatusInactive {
+			registry.Suspend(tenant)
+			break
+		}
+		_ = registry.Provision(tenant, nil)
 	case enums.DeleteTenantConnect:
-		DeleteInstance(tenantCode)
-		break
+		registry.Remove(tenantCode, false)
 	case enums.DropTenantConnect:
-		DropInstance(tenantCode)
-		break
+		registry.Remove(tenantCode, true)
+	case enums.SuspendTenantConnect:
+		registry.Suspend(tenant)
+	case enums.SettingsTenantConnect:
+		registry.UpdateSettings(tenantCode, tenant.Settings)
 	default:
 		fmt.Println("Cannot handle tenant mode", mode)
+		return
+	}
+	publish(mode, tenantCode)
+}
+
+// Open connects to a tenant's database outside the registry, without
+// creating or migrating it. The caller closes it with Destruction.
+func Open(tenant *models.Tenant) (*TenantInfo, error) {
+	info := &TenantInfo{Info: tenant}
+	if err := info.Connect(); err != nil {
+		info.Destruction()
+		return nil, err
 	}
+	return info, nil
 }
@@ -0,0 +1,30 @@
+package tenantProvider
+
+import (
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/serviceProvider"
+	"log"
+)
+
+// provisionTenant runs a provisioning job through the registry, recording
+// every step on the job.
+func provisionTenant(job *models.TenantProvisioning, tenant *models.Tenant) {
+	provisioning := serviceProvider.GetInstance().ProvisioningService
+
+	run := func(step enums.ProvisioningStep, fn func() error) error {
+		record := provisioning.StartStep(job, step)
+		err := fn()
+		provisioning.FinishStep(record, err)
+		return err
+	}
+
+	err := registry.Provision(tenant, run)
+	if err != nil {
+		log.Printf("tenant %s provisioning failed: %v", tenant.Code, err)
+	}
+	provisioning.Complete(job, err)
+	if err == nil {
+		publish(enums.AddTenantConnect, tenant.Code)
+	}
+}
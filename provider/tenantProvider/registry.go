@@ -0,0 +1,251 @@
+package tenantProvider
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTenantNotFound    = errors.New("tenant not found")
+	ErrTenantUnavailable = errors.New("tenant is not available")
+	ErrTenantSuspended   = errors.New("tenant is suspended")
+)
+
+// entry is one generation of a tenant's connection pool. Replacing a tenant
+// (edit, suspend, delete) puts the old entry into draining; its pool is only
+// closed once the last request holding it has released it.
+type entry struct {
+	info      *TenantInfo
+	state     enums.TenantState
+	refs      int64
+	err       string
+	drop      bool
+	updatedAt time.Time
+}
+
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Acquire returns the tenant's services and a release func that must be
+// called once the caller no longer uses them.
+func (r *Registry) Acquire(tenantCode string) (*TenantInfo, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[tenantCode]
+	if !ok || e.state == enums.TenantStateRemoved {
+		return nil, nil, ErrTenantNotFound
+	}
+	switch e.state {
+	case enums.TenantStateReady:
+	case enums.TenantStateSuspended:
+		return nil, nil, ErrTenantSuspended
+	default:
+		return nil, nil, ErrTenantUnavailable
+	}
+
+	e.refs++
+	var once sync.Once
+	return e.info, func() { once.Do(func() { r.release(e) }) }, nil
+}
+
+func (r *Registry) release(e *entry) {
+	r.mu.Lock()
+	e.refs--
+	closeNow := e.refs == 0 && e.state == enums.TenantStateDraining
+	if closeNow {
+		e.setState(enums.TenantStateRemoved)
+	}
+	r.mu.Unlock()
+
+	if closeNow {
+		e.close()
+	}
+}
+
+// Get returns the tenant without taking a reference. Only for callers that
+// are not racing with tenant changes, such as one-off CLI commands.
+func (r *Registry) Get(tenantCode string) *TenantInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if e, ok := r.entries[tenantCode]; ok && e.state == enums.TenantStateReady {
+		return e.info
+	}
+	return nil
+}
+
+func (r *Registry) State(tenantCode string) (*dto.TenantStateResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.entries[tenantCode]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+	return &dto.TenantStateResponse{
+		Code:      tenantCode,
+		State:     e.state,
+		InFlight:  e.refs,
+		Error:     e.err,
+		UpdatedAt: e.updatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (r *Registry) Codes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	codes := make([]string, 0, len(r.entries))
+	for code, e := range r.entries {
+		if e.state == enums.TenantStateReady {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Registered returns every tenant that is not removed, whatever its state.
+func (r *Registry) Registered() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	codes := make([]string, 0, len(r.entries))
+	for code, e := range r.entries {
+		if e.state != enums.TenantStateRemoved {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// UpdateSettings swaps the settings of the tenant in place, without
+// reconnecting.
+func (r *Registry) UpdateSettings(tenantCode string, settings models.TenantSettings) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if e, ok := r.entries[tenantCode]; ok {
+		e.info.SetSettings(settings)
+	}
+}
+
+// Provision registers the tenant as provisioning, connects it outside the
+// lock and then publishes it as ready (or failed). Any previous generation
+// is drained. With a StepRunner the steps are tracked and a failure is
+// rolled back; without one the tenant is only reconnected.
+func (r *Registry) Provision(tenant *models.Tenant, run StepRunner) error {
+	e := &entry{info: &TenantInfo{Info: tenant}}
+	e.setState(enums.TenantStateProvisioning)
+	r.swap(tenant.Code, e)
+
+	err := e.info.Init(run)
+	if err != nil && run != nil {
+		if rbErr := run(enums.ProvisioningStepRollback, e.info.Rollback); rbErr != nil {
+			log.Printf("tenant %s rollback: %v", tenant.Code, rbErr)
+		}
+	} else if err != nil {
+		e.info.Destruction()
+	}
+
+	r.mu.Lock()
+	if e.state != enums.TenantStateProvisioning {
+		// replaced or removed while connecting, drain already gave up on it
+		r.mu.Unlock()
+		e.close()
+		return err
+	}
+	if err != nil {
+		e.err = err.Error()
+		e.setState(enums.TenantStateFailed)
+	} else {
+		e.setState(enums.TenantStateReady)
+	}
+	r.mu.Unlock()
+	return err
+}
+
+// Suspend keeps the tenant registered without a pool so requests can be
+// rejected as suspended rather than unknown.
+func (r *Registry) Suspend(tenant *models.Tenant) {
+	e := &entry{info: &TenantInfo{Info: tenant}}
+	e.setState(enums.TenantStateSuspended)
+	r.swap(tenant.Code, e)
+}
+
+// MarkFailed registers a tenant whose provisioning did not complete, without
+// connecting it.
+func (r *Registry) MarkFailed(tenant *models.Tenant, reason string) {
+	e := &entry{info: &TenantInfo{Info: tenant}, err: reason}
+	e.setState(enums.TenantStateFailed)
+	r.swap(tenant.Code, e)
+}
+
+// Remove drains the tenant and leaves it registered as a tombstone, so its
+// state stays queryable as draining and then removed.
+func (r *Registry) Remove(tenantCode string, drop bool) {
+	r.mu.RLock()
+	e, ok := r.entries[tenantCode]
+	r.mu.RUnlock()
+	if ok {
+		r.drain(e, drop)
+	}
+}
+
+func (r *Registry) swap(tenantCode string, next *entry) {
+	r.mu.Lock()
+	prev, ok := r.entries[tenantCode]
+	r.entries[tenantCode] = next
+	r.mu.Unlock()
+	if ok {
+		r.drain(prev, false)
+	}
+}
+
+func (r *Registry) drain(e *entry, drop bool) {
+	r.mu.Lock()
+	if e.state == enums.TenantStateRemoved || e.state == enums.TenantStateDraining {
+		e.drop = e.drop || drop
+		r.mu.Unlock()
+		return
+	}
+	e.drop = drop
+	if e.state == enums.TenantStateProvisioning {
+		// Provision closes it once Init returns
+		e.setState(enums.TenantStateRemoved)
+		r.mu.Unlock()
+		return
+	}
+	e.setState(enums.TenantStateDraining)
+	closeNow := e.refs == 0
+	if closeNow {
+		e.setState(enums.TenantStateRemoved)
+	}
+	r.mu.Unlock()
+
+	if closeNow {
+		e.close()
+	}
+}
+
+func (e *entry) setState(state enums.TenantState) {
+	e.state = state
+	e.updatedAt = time.Now()
+}
+
+func (e *entry) close() {
+	if e.drop {
+		e.info.Drop()
+		return
+	}
+	e.info.Destruction()
+	log.Printf("tenant %s pool closed", e.info.Info.Code)
+}
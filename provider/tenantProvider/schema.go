@@ -0,0 +1,65 @@
+package tenantProvider
+
+import (
+	"context"
+	"golang-rest-user/dto"
+	"golang-rest-user/migration"
+	"golang-rest-user/provider/mySqlProvider"
+	"golang-rest-user/utils"
+)
+
+func toSchemaStatus(status *migration.Status) dto.SchemaStatus {
+	return dto.SchemaStatus{
+		Version: status.Version,
+		Latest:  status.Latest,
+		Pending: status.Pending,
+		Dirty:   status.Dirty,
+	}
+}
+
+// SchemaVersions reports the schema version of every registered tenant.
+// Tenants without a pool (suspended, failed, ...) only report their state.
+func SchemaVersions(ctx context.Context, concurrency int) []dto.TenantSchemaResponse {
+	codes := registry.Registered()
+	result := make([]dto.TenantSchemaResponse, len(codes))
+	utils.ParallelEach(len(codes), concurrency, func(i int) {
+		item := &result[i]
+		item.Code = codes[i]
+		item.Latest = migration.Latest(migration.Tenant)
+		if state, err := registry.State(codes[i]); err == nil {
+			item.State = state.State
+		}
+
+		info, release, err := registry.Acquire(codes[i])
+		if err != nil {
+			item.Error = err.Error()
+			return
+		}
+		defer release()
+
+		migrator, err := info.Migrator()
+		if err != nil {
+			item.Error = err.Error()
+			return
+		}
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			item.Error = err.Error()
+			return
+		}
+		item.SchemaStatus = toSchemaStatus(status)
+	})
+	return result
+}
+
+func MasterSchemaVersion(ctx context.Context) (dto.SchemaStatus, error) {
+	migrator, err := mySqlProvider.Migrator()
+	if err != nil {
+		return dto.SchemaStatus{}, err
+	}
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return dto.SchemaStatus{}, err
+	}
+	return toSchemaStatus(status), nil
+}
@@ -1,6 +1,9 @@
 package tenantProvider
 
 import (
+	"context"
+	"golang-rest-user/enums"
+	"golang-rest-user/migration"
 	"golang-rest-user/models"
 	"golang-rest-user/provider/mySqlProvider"
 	"golang-rest-user/provider/serviceProvider"
@@ -8,84 +11,195 @@ import (
 	"golang-rest-user/service"
 	"golang-rest-user/utils"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type TenantInfo struct {
-	Info         *models.Tenant
-	db           *gorm.DB
-	UserService  service.UserService
-	AuthService  service.AuthService
-	ZoneService  service.ZoneService
-	ShareService service.ShareService
+	Info           *models.Tenant
+	db             *gorm.DB
+	createdDB      bool
+	settings       atomic.Pointer[models.TenantSettings]
+	UserService    service.UserService
+	AuthService    service.AuthService
+	ZoneService    service.ZoneService
+	ShareService   service.ShareService
+	SessionService service.SessionService
+	PasswordReset  service.PasswordResetService
+	Verification   service.EmailVerificationService
+	MFAService     service.MFAService
+	ZoneRoles      service.ZoneRoleService
+	ZoneTypes      service.ZoneTypeService
 }
 
-func (t *TenantInfo) Init() error {
+// StepRunner wraps each provisioning step, e.g. to record its progress.
+type StepRunner func(step enums.ProvisioningStep, run func() error) error
+
+func runStep(_ enums.ProvisioningStep, run func() error) error {
+	return run()
+}
+
+func (t *TenantInfo) credentials() (string, string) {
 	decryptedDBUser, _ := utils.AESGCMDecrypt(t.Info.DBUser)
 	decryptedDBPass, _ := utils.AESGCMDecrypt(t.Info.DBPass)
+	return decryptedDBUser, decryptedDBPass
+}
+
+func (t *TenantInfo) Init(run StepRunner) error {
+	if run == nil {
+		run = runStep
+	}
+	if err := run(enums.ProvisioningStepCreateDatabase, t.CreateDatabase); err != nil {
+		return err
+	}
+	if err := run(enums.ProvisioningStepConnect, t.Connect); err != nil {
+		return err
+	}
+	if err := run(enums.ProvisioningStepMigrate, t.Migrate); err != nil {
+		return err
+	}
+	return run(enums.ProvisioningStepSeed, t.Seed)
+}
+
+func (t *TenantInfo) CreateDatabase() error {
+	dbUser, dbPass := t.credentials()
 
-	err := mySqlProvider.CreateDB(decryptedDBUser, decryptedDBPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName)
+	exists, err := mySqlProvider.DatabaseExists(dbUser, dbPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
+	if exists {
+		return nil
+	}
+	if err := mySqlProvider.CreateDB(dbUser, dbPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName); err != nil {
+		log.Println(err)
+		return err
+	}
+	t.createdDB = true
+	return nil
+}
+
+func (t *TenantInfo) Connect() error {
+	dbUser, dbPass := t.credentials()
 
-	t.db, _ = mySqlProvider.CreateInstanceDB(decryptedDBUser, decryptedDBPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName)
+	var err error
+	t.db, err = mySqlProvider.CreateInstanceDB(dbUser, dbPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName)
+	if err != nil {
+		return err
+	}
 
 	sqlDB, err := t.db.DB()
-	if err == nil {
-		sqlDB.SetMaxIdleConns(10)
-		sqlDB.SetMaxOpenConns(100)
-		sqlDB.SetConnMaxLifetime(time.Hour)
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxIdleConns(10)
+	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+	if err := sqlDB.Ping(); err != nil {
+		return err
 	}
 
 	t.InitService()
-	t.Migrate()
 	return nil
 }
 
+// Settings returns the tenant's current settings. They can change while the
+// pool stays up, so services read them through this instead of Info.
+func (t *TenantInfo) Settings() models.TenantSettings {
+	if s := t.settings.Load(); s != nil {
+		return *s
+	}
+	return t.Info.Settings
+}
+
+func (t *TenantInfo) SetSettings(settings models.TenantSettings) {
+	t.settings.Store(&settings)
+}
+
 func (t *TenantInfo) InitService() {
 	appService := serviceProvider.GetInstance()
 
 	userRepo := repository.NewUserRepo(t.db)
-	t.UserService = service.NewUserService(t.Info.Code, userRepo)
+	t.UserService = service.NewUserService(t.Info.Code, userRepo, appService.Passwords, t.Settings)
 
 	jwtManager := appService.JWTManager
-	t.AuthService = service.NewAuthService(userRepo, jwtManager)
+	t.Verification = service.NewEmailVerificationService(t.Info.Code, userRepo, appService.Mailer, appService.PublicURL)
+	t.MFAService = service.NewMFAService(t.Info.Code, t.Info.Name, userRepo, repository.NewRecoveryCodeRepo(t.db), t.Settings)
+	t.AuthService = service.NewAuthService(userRepo, jwtManager, appService.Passwords, t.Settings, t.Verification, t.MFAService)
+	t.SessionService = service.NewSessionService(t.Info.Code)
+	t.PasswordReset = service.NewPasswordResetService(t.Info.Code, userRepo, appService.Passwords, appService.Mailer, appService.PublicURL)
 
 	zoneRepo := repository.NewZoneRepo(t.db)
 	userZoneRepo := repository.NewUserZoneRepo(t.db)
-	t.ZoneService = service.NewZoneService(zoneRepo, userZoneRepo)
-	t.ShareService = service.NewShareService(userZoneRepo, zoneRepo, userRepo)
+	t.ZoneRoles = service.NewZoneRoleService(repository.NewZoneRoleRepo(t.db), userZoneRepo)
+	t.ZoneTypes = service.NewZoneTypeService(repository.NewZoneTypeRepo(t.db), zoneRepo)
+	zoneAuthorizer := service.NewZoneAuthorizer(userZoneRepo, t.ZoneRoles)
+	t.ZoneService = service.NewZoneService(zoneRepo, userZoneRepo, repository.NewZoneTrashRepo(t.db), zoneAuthorizer, t.ZoneTypes)
+	t.ShareService = service.NewShareService(userZoneRepo, zoneRepo, userRepo, zoneAuthorizer)
 }
 
-func (t *TenantInfo) Migrate() {
+func (t *TenantInfo) Migrator() (*migration.Migrator, error) {
+	if t.db == nil {
+		return nil, ErrTenantUnavailable
+	}
+	sqlDB, err := t.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migration.NewMigrator(sqlDB, migration.Tenant), nil
+}
 
-	err := t.db.AutoMigrate(
-		&models.User{},
-		&models.Zone{},
-		&models.UserZone{},
-	)
+func (t *TenantInfo) Migrate() error {
+	migrator, err := t.Migrator()
 	if err != nil {
-		log.Println(err)
+		return err
 	}
+	result, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Printf("tenant %s migrate: %v", t.Info.Code, err)
+		return err
+	}
+	if len(result.Applied) > 0 {
+		log.Printf("tenant %s migrated from %d to %d", t.Info.Code, result.From, result.To)
+	}
+	return nil
+}
+
+// Seed inserts the default data a new tenant DB needs. Nothing yet.
+func (t *TenantInfo) Seed() error {
+	return nil
+}
+
+// Rollback undoes a failed provisioning: the pool is closed and the database
+// is dropped, but only if this provisioning created it.
+func (t *TenantInfo) Rollback() error {
+	t.Destruction()
+	if !t.createdDB {
+		return nil
+	}
+	dbUser, dbPass := t.credentials()
+	return mySqlProvider.DropDB(dbUser, dbPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName)
 }
 
 func (t *TenantInfo) Destruction() {
+	if t.db == nil {
+		return
+	}
 	db, err := t.db.DB()
 	if err != nil {
 		log.Println(err)
+		return
 	}
 	defer db.Close()
 }
 
 func (t *TenantInfo) Drop() {
-	db, err := t.db.DB()
-	if err != nil {
+	t.Destruction()
+	dbUser, dbPass := t.credentials()
+	if err := mySqlProvider.DropDB(dbUser, dbPass, t.Info.DBHost, t.Info.DBPort, t.Info.DBName); err != nil {
 		log.Println(err)
 	}
-	defer db.Close()
-	db.Exec("DROP TABLE IF EXISTS %s", t.Info.DBName)
 }
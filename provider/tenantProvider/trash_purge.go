@@ -0,0 +1,72 @@
+package tenantProvider
+
+import (
+	"context"
+	"golang-rest-user/provider/redisProvider"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	trashPurgeInterval = time.Hour
+	// trashPurgeLockTTL frees the lock of an instance that died mid-purge
+	trashPurgeLockTTL = 10 * time.Minute
+)
+
+// StartTrashPurge purges, every hour until ctx is done, the zones each ready
+// tenant has kept in the trash for longer than its retention setting. Every
+// API instance runs it, a per-tenant Redis lock lets one purge at a time.
+func StartTrashPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purgeTrash()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func purgeTrash() {
+	for _, code := range registry.Codes() {
+		purgeTenantTrash(code)
+	}
+}
+
+func purgeTenantTrash(code string) {
+	token := uuid.New().String()
+	locked, err := redisProvider.LockTrashPurge(code, token, trashPurgeLockTTL)
+	if err != nil {
+		log.Printf("tenant %s trash purge lock: %v", code, err)
+		return
+	}
+	if !locked {
+		return
+	}
+	defer func() {
+		if err := redisProvider.UnlockTrashPurge(code, token); err != nil {
+			log.Printf("tenant %s trash purge unlock: %v", code, err)
+		}
+	}()
+
+	info, release, err := registry.Acquire(code)
+	if err != nil {
+		return
+	}
+	defer release()
+	retention := info.Settings().Zones.WithDefaults().TrashRetentionDays
+	purged, err := info.ZoneService.PurgeExpiredTrash(time.Now().AddDate(0, 0, -retention))
+	if err != nil {
+		log.Printf("tenant %s trash purge: %v", code, err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("tenant %s purged %d zones from the trash", code, purged)
+	}
+}
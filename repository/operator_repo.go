@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"golang-rest-user/models"
+
+	"gorm.io/gorm"
+)
+
+type OperatorRepo interface {
+	Create(*models.Operator) error
+	GetByID(uint) (*models.Operator, error)
+	GetByUUID(string) (*models.Operator, error)
+	GetByUsername(string) (*models.Operator, error)
+	GetList(page, pageSize int, search string) (operators []models.Operator, total int64, err error)
+	Update(*models.Operator) error
+	DeleteByID(uint) error
+	Count() (int64, error)
+}
+
+type operatorRepo struct {
+	db *gorm.DB
+}
+
+func NewOperatorRepo(db *gorm.DB) OperatorRepo {
+	return &operatorRepo{db: db}
+}
+
+func (r *operatorRepo) Create(operator *models.Operator) error {
+	return r.db.Create(operator).Error
+}
+
+func (r *operatorRepo) GetByID(id uint) (*models.Operator, error) {
+	var o models.Operator
+	if err := r.db.First(&o, id).Error; err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *operatorRepo) GetByUUID(uuid string) (*models.Operator, error) {
+	var o models.Operator
+	if err := r.db.Where("uuid = ?", uuid).First(&o).Error; err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *operatorRepo) GetByUsername(username string) (*models.Operator, error) {
+	var o models.Operator
+	if err := r.db.Where("username = ?", username).First(&o).Error; err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *operatorRepo) GetList(page, pageSize int, search string) (operators []models.Operator, total int64, err error) {
+	offset := (page - 1) * pageSize
+	query := r.db.Model(&models.Operator{})
+	query = query.Where("username LIKE ?", "%"+search+"%")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id asc").Offset(offset).Limit(pageSize).Find(&operators).Error; err != nil {
+		return nil, 0, err
+	}
+	return operators, total, nil
+}
+
+func (r *operatorRepo) Update(operator *models.Operator) error {
+	return r.db.Save(operator).Error
+}
+
+func (r *operatorRepo) DeleteByID(id uint) error {
+	return r.db.Delete(&models.Operator{}, id).Error
+}
+
+func (r *operatorRepo) Count() (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Operator{}).Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+
+	"gorm.io/gorm"
+)
+
+type ProvisioningRepo interface {
+	Create(*models.TenantProvisioning) error
+	Update(*models.TenantProvisioning) error
+	GetByUUID(string) (*models.TenantProvisioning, error)
+	GetUnfinished() ([]models.TenantProvisioning, error)
+	CreateStep(*models.TenantProvisioningStep) error
+	UpdateStep(*models.TenantProvisioningStep) error
+}
+
+type provisioningRepo struct {
+	db *gorm.DB
+}
+
+func NewProvisioningRepo(db *gorm.DB) ProvisioningRepo {
+	return &provisioningRepo{db: db}
+}
+
+func (r *provisioningRepo) Create(job *models.TenantProvisioning) error {
+	return r.db.Omit("Steps").Create(job).Error
+}
+
+func (r *provisioningRepo) Update(job *models.TenantProvisioning) error {
+	return r.db.Omit("Steps").Save(job).Error
+}
+
+func (r *provisioningRepo) GetByUUID(uuid string) (*models.TenantProvisioning, error) {
+	var job models.TenantProvisioning
+	err := r.db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id asc")
+	}).Where("uuid = ?", uuid).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *provisioningRepo) GetUnfinished() (jobs []models.TenantProvisioning, err error) {
+	err = r.db.Where("status IN ?", []enums.ProvisioningStatus{enums.ProvisioningPending, enums.ProvisioningRunning}).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *provisioningRepo) CreateStep(step *models.TenantProvisioningStep) error {
+	return r.db.Create(step).Error
+}
+
+func (r *provisioningRepo) UpdateStep(step *models.TenantProvisioningStep) error {
+	return r.db.Save(step).Error
+}
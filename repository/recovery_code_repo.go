@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"golang-rest-user/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepo interface {
+	// Replace deletes the user's codes and stores the new hashes.
+	Replace(userID uint, hashes []string) error
+	// Use marks an unused code as used and reports whether there was one.
+	Use(userID uint, hash string) (bool, error)
+	CountUnused(userID uint) (int64, error)
+	DeleteByUser(userID uint) error
+}
+
+type recoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepo(db *gorm.DB) RecoveryCodeRepo {
+	return &recoveryCodeRepo{db: db}
+}
+
+func (r *recoveryCodeRepo) Replace(userID uint, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.UserRecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			codes[i] = models.UserRecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *recoveryCodeRepo) Use(userID uint, hash string) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now().UTC())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *recoveryCodeRepo) CountUnused(userID uint) (count int64, err error) {
+	err = r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return
+}
+
+func (r *recoveryCodeRepo) DeleteByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error
+}
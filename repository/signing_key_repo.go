@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepo interface {
+	// ListVerifiable returns the active keys and the keys retired after since.
+	ListVerifiable(since time.Time) ([]models.SigningKey, error)
+	List() ([]models.SigningKey, error)
+	// Rotate retires every active key and stores key as the new active one.
+	Rotate(key *models.SigningKey) error
+}
+
+type signingKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepo(db *gorm.DB) SigningKeyRepo {
+	return &signingKeyRepo{db: db}
+}
+
+func (r *signingKeyRepo) ListVerifiable(since time.Time) (keys []models.SigningKey, err error) {
+	err = r.db.Where("status = ? OR retired_at > ?", enums.SigningKeyActive, since).
+		Order("id desc").Find(&keys).Error
+	return
+}
+
+func (r *signingKeyRepo) List() (keys []models.SigningKey, err error) {
+	err = r.db.Order("id desc").Find(&keys).Error
+	return
+}
+
+func (r *signingKeyRepo) Rotate(key *models.SigningKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.SigningKey{}).
+			Where("status = ?", enums.SigningKeyActive).
+			Updates(map[string]interface{}{"status": enums.SigningKeyRetired, "retired_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"golang-rest-user/enums"
 	"golang-rest-user/models"
 
 	"gorm.io/gorm"
@@ -17,6 +18,8 @@ type TenantRepo interface {
 	GetByDBName(tenantCode string) (*models.Tenant, error)
 	RecoverDeleted(id uint) error
 	FindDeletedByCode(string) (*models.Tenant, error)
+	UpdateStatus(id uint, status enums.TenantStatus) error
+	UpdateSettings(id uint, settings models.TenantSettings) error
 }
 
 type tenantRepo struct {
@@ -101,3 +104,13 @@ func (r *tenantRepo) FindDeletedByCode(tenantCode string) (*models.Tenant, error
 
 	return &tenant, nil
 }
+
+func (r *tenantRepo) UpdateStatus(id uint, status enums.TenantStatus) error {
+	return r.db.Model(&models.Tenant{}).Where("id = ?", id).
+		Update("status", status).Error
+}
+
+func (r *tenantRepo) UpdateSettings(id uint, settings models.TenantSettings) error {
+	return r.db.Model(&models.Tenant{}).Where("id = ?", id).
+		Select("Settings").Updates(&models.Tenant{Settings: settings}).Error
+}
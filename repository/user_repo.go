@@ -1,19 +1,34 @@
 package repository
 
 import (
+	"errors"
+	"golang-rest-user/enums"
 	"golang-rest-user/models"
+	"slices"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrLastAdmin = errors.New("the tenant must keep at least one tenant_admin")
+
 type UserRepo interface {
 	Create(*models.User) error
 	GetByID(uint) (*models.User, error)
 	GetList(page, pageSize int, search string) (users []models.User, total int64, err error)
 	Update(*models.User) error
+	// DeleteByIDs fails with ErrLastAdmin rather than delete every tenant_admin.
 	DeleteByIDs([]uint) (deleted int64, err error)
 	GetByUsername(string) (*models.User, error)
 	GetByUUID(string) (*models.User, error)
+	UpdatePassword(id uint, password string) error
+	MarkEmailVerified(id uint) error
+	// UpdateRole fails with ErrLastAdmin rather than demote the only tenant_admin.
+	UpdateRole(id uint, role enums.TenantRole) error
+	// UpdateMFA stores the encrypted TOTP secret; an empty secret disables MFA.
+	UpdateMFA(id uint, secret string, enabled bool) error
+	CountLegacyPasswords() (legacy int64, total int64, err error)
 }
 
 type userRepo struct {
@@ -61,9 +76,16 @@ func (r *userRepo) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
-func (r *userRepo) DeleteByIDs(ids []uint) (int64, error) {
-	res := r.db.Delete(&models.User{}, ids)
-	return res.RowsAffected, res.Error
+func (r *userRepo) DeleteByIDs(ids []uint) (deleted int64, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := keepAnAdmin(tx, ids); err != nil {
+			return err
+		}
+		res := tx.Delete(&models.User{}, ids)
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	return
 }
 
 func (r *userRepo) GetByUUID(uuid string) (*models.User, error) {
@@ -73,3 +95,68 @@ func (r *userRepo) GetByUUID(uuid string) (*models.User, error) {
 	}
 	return &u, nil
 }
+
+func (r *userRepo) UpdatePassword(id uint, password string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).
+		Update("password", password).Error
+}
+
+func (r *userRepo) MarkEmailVerified(id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"email_verified": true, "email_verified_at": time.Now().UTC()}).Error
+}
+
+func (r *userRepo) UpdateRole(id uint, role enums.TenantRole) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if role != enums.TenantRoleAdmin {
+			if err := keepAnAdmin(tx, []uint{id}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.User{}).Where("id = ?", id).
+			Update("role", role).Error
+	})
+}
+
+// keepAnAdmin locks the tenant_admin rows until tx ends, so concurrent
+// demotions and deletions are serialised, and fails if removing ids would
+// leave no tenant_admin behind.
+func keepAnAdmin(tx *gorm.DB, ids []uint) error {
+	var admins []uint
+	err := tx.Model(&models.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ?", enums.TenantRoleAdmin).Pluck("id", &admins).Error
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if !slices.Contains(ids, admin) {
+			return nil
+		}
+	}
+	if len(admins) > 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+func (r *userRepo) UpdateMFA(id uint, secret string, enabled bool) error {
+	var enabledAt *time.Time
+	if enabled {
+		now := time.Now().UTC()
+		enabledAt = &now
+	}
+	return r.db.Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"mfa_secret": secret, "mfa_enabled": enabled, "mfa_enabled_at": enabledAt}).Error
+}
+
+// CountLegacyPasswords counts users whose password is still an AES-GCM
+// ciphertext. Hashed passwords always start with '$'.
+func (r *userRepo) CountLegacyPasswords() (legacy int64, total int64, err error) {
+	if err = r.db.Model(&models.User{}).Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	if err = r.db.Model(&models.User{}).Where("password NOT LIKE ?", "$%").Count(&legacy).Error; err != nil {
+		return 0, 0, err
+	}
+	return legacy, total, nil
+}
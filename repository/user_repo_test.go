@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"golang-rest-user/enums"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+const lockAdmins = "SELECT `id` FROM `users` WHERE role = ? AND `users`.`deleted_at` IS NULL FOR UPDATE"
+
+func TestUpdateRoleKeepsAnAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		admins  []uint
+		wantErr error
+	}{
+		{name: "last admin", admins: []uint{7}, wantErr: ErrLastAdmin},
+		{name: "another admin left", admins: []uint{3, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			rows := sqlmock.NewRows([]string{"id"})
+			for _, id := range tt.admins {
+				rows.AddRow(id)
+			}
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(lockAdmins)).
+				WithArgs(enums.TenantRoleAdmin).WillReturnRows(rows)
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `role`=?")).
+					WithArgs(enums.TenantRoleMember, sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			err := NewUserRepo(db).UpdateRole(7, enums.TenantRoleMember)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDeleteByIDsKeepsAnAdmin(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(lockAdmins)).
+		WithArgs(enums.TenantRoleAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(7))
+	mock.ExpectRollback()
+
+	if _, err := NewUserRepo(db).DeleteByIDs([]uint{3, 5, 7}); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("err = %v, want %v", err, ErrLastAdmin)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,12 +9,25 @@ import (
 
 type UserZoneRepo interface {
 	Create(*models.UserZone) error
+	Get(userID, zoneID uint) (*models.UserZone, error)
 	UpdatePermission(userID, zoneID uint, permission enums.UserPermission) error
 	Delete(userID, zoneID uint) (int64, error)
 	GetPermission(userID uint, path string) (string, error)
-	GetZoneID(userID uint) (uint, error)
 	GetSharedUser(uint) ([]models.UserZone, error)
 	GetSharedZone(uint) ([]models.UserZone, error)
+	ListGrants(userID uint) ([]ZoneGrant, error)
+	CountByPermission(permission enums.UserPermission) (int64, error)
+}
+
+// ZoneGrant is a zone the user holds a permission on directly, with the
+// number of zones below it that the permission extends to.
+type ZoneGrant struct {
+	UUID        string
+	Name        string
+	Type        string
+	Path        string
+	Permission  enums.UserPermission
+	Descendants int64
 }
 
 type userZoneRepoImpl struct {
@@ -29,19 +42,28 @@ func (r *userZoneRepoImpl) GetSharedZone(userID uint) (userZones []models.UserZo
 	return userZones, nil
 }
 
-func (r *userZoneRepoImpl) GetZoneID(userID uint) (uint, error) {
-	var userZone models.UserZone
-	err := r.db.Table("user_zones").Where("user_id = ?", userID).First(&userZone).Error
+func (r *userZoneRepoImpl) ListGrants(userID uint) (grants []ZoneGrant, err error) {
+	err = r.db.Table("user_zones uz").
+		Select(`z.uuid, z.name, z.type, z.path, uz.permission,
+			(SELECT COUNT(*) FROM zones d WHERE d.path LIKE CONCAT(z.path, '%') AND d.deleted_at IS NULL) - 1 AS descendants`).
+		Joins("JOIN zones z ON uz.zone_id = z.id AND z.deleted_at IS NULL").
+		Where("uz.user_id = ? AND uz.deleted_at IS NULL", userID).
+		Order("z.path ASC").
+		Scan(&grants).Error
+	return
+}
 
-	return userZone.ZoneID, err
+func (r *userZoneRepoImpl) CountByPermission(permission enums.UserPermission) (count int64, err error) {
+	err = r.db.Model(&models.UserZone{}).Where("permission = ?", permission).Count(&count).Error
+	return
 }
 
 func (r *userZoneRepoImpl) GetPermission(userID uint, path string) (string, error) {
 	var permission string
 	err := r.db.Table("user_zones uz").
 		Select("uz.permission").
-		Joins("JOIN zones z on uz.zone_id = z.id").
-		Where("uz.user_id = ? AND ? LIKE CONCAT(z.path, '%')", userID, path).
+		Joins("JOIN zones z on uz.zone_id = z.id AND z.deleted_at IS NULL").
+		Where("uz.user_id = ? AND uz.deleted_at IS NULL AND ? LIKE CONCAT(z.path, '%')", userID, path).
 		Order("z.level DESC").
 		Limit(1).Scan(&permission).Error
 	if err != nil {
@@ -62,6 +84,14 @@ func (r *userZoneRepoImpl) Create(userZone *models.UserZone) error {
 	return r.db.Create(userZone).Error
 }
 
+func (r *userZoneRepoImpl) Get(userID, zoneID uint) (*models.UserZone, error) {
+	var userZone models.UserZone
+	if err := r.db.Where("user_id = ? AND zone_id = ?", userID, zoneID).First(&userZone).Error; err != nil {
+		return nil, err
+	}
+	return &userZone, nil
+}
+
 func (r *userZoneRepoImpl) UpdatePermission(userID, zoneID uint, permission enums.UserPermission) error {
 	return r.db.Model(&models.UserZone{}).Where("user_id = ? AND zone_id = ?", userID, zoneID).
 		Update("permission", permission).Error
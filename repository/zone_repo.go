@@ -1,19 +1,30 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"golang-rest-user/models"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrZoneCycle = errors.New("cannot move a zone into its own subtree")
+
 type ZoneRepo interface {
 	Create(*models.Zone) error
 	Update(*models.Zone) error
-	DeleteByPath(string) (deleted int64, err error)
 	GetByID(uint) (*models.Zone, error)
 	GetByUUID(string) (*models.Zone, error)
 	UpdateZonePath(uint, string) error
 	GetSubtreeByPath(path string) ([]models.Zone, error)
+	MoveSubtree(zoneID, parentID uint) (moved int64, err error)
+	GetByIDs(ids []uint) ([]models.Zone, error)
+	GetSubtreeToLevel(path string, maxLevel int, withMetadata bool) ([]models.Zone, error)
+	CountChildren(ids []uint) (map[uint]int64, error)
+	CountByType(zoneType string) (int64, error)
 }
 
 type zoneRepoImpl struct {
@@ -37,6 +48,61 @@ func (r *zoneRepoImpl) GetSubtreeByPath(path string) ([]models.Zone, error) {
 	return zones, nil
 }
 
+func (r *zoneRepoImpl) GetByIDs(ids []uint) ([]models.Zone, error) {
+	var zones []models.Zone
+	if len(ids) == 0 {
+		return zones, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Order("level ASC").Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// GetSubtreeToLevel is GetSubtreeByPath cut off below maxLevel. Metadata is
+// only loaded when asked for, it is the bulk of a large tree.
+func (r *zoneRepoImpl) GetSubtreeToLevel(path string, maxLevel int, withMetadata bool) ([]models.Zone, error) {
+	var zones []models.Zone
+	query := r.db.Where("path LIKE ? AND level <= ?", path+"%", maxLevel).Order("level ASC, id ASC")
+	if !withMetadata {
+		query = query.Omit("metadata")
+	}
+	if err := query.Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// CountChildren returns the number of direct children of each zone that has any.
+func (r *zoneRepoImpl) CountChildren(ids []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ParentID uint
+		Count    int64
+	}
+	err := r.db.Model(&models.Zone{}).
+		Select("parent_id, COUNT(*) AS count").
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ParentID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *zoneRepoImpl) CountByType(zoneType string) (count int64, err error) {
+	// trashed zones count too, they would come back with the type on restore
+	err = r.db.Unscoped().Model(&models.Zone{}).Where("type = ?", zoneType).Count(&count).Error
+	return
+}
+
 func NewZoneRepo(db *gorm.DB) ZoneRepo {
 	return &zoneRepoImpl{db: db}
 }
@@ -49,11 +115,6 @@ func (r *zoneRepoImpl) Update(zone *models.Zone) error {
 	return r.db.Save(zone).Error
 }
 
-func (r *zoneRepoImpl) DeleteByPath(path string) (deleted int64, err error) {
-	res := r.db.Where("path LIKE ?", path+"%").Delete(&models.Zone{})
-	return res.RowsAffected, res.Error
-}
-
 func (r *zoneRepoImpl) GetByID(id uint) (*models.Zone, error) {
 	var zone models.Zone
 	if err := r.db.First(&zone, id).Error; err != nil {
@@ -82,3 +143,68 @@ func (r *zoneRepoImpl) GetByPath(path string) ([]models.Zone, error) {
 	}
 	return zones, nil
 }
+
+// MoveSubtree puts the zone under parent and rewrites path and level of the
+// whole subtree, trashed zones included so they can still be restored. The
+// subtree and the parent's ancestors are locked before the cycle check is
+// repeated: a concurrent move of one of those ancestors into the subtree
+// touches rows of both sets, so the two moves cannot pass it together. It
+// returns the number of live zones moved.
+func (r *zoneRepoImpl) MoveSubtree(zoneID, parentID uint) (moved int64, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var zone, parent models.Zone
+		locked := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Session(&gorm.Session{})
+		if err := locked.First(&zone, zoneID).Error; err != nil {
+			return err
+		}
+		if err := locked.First(&parent, parentID).Error; err != nil {
+			return err
+		}
+		var ids []uint
+		err := locked.Unscoped().Model(&models.Zone{}).Where("path LIKE ?", zone.Path+"%").Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if err := locked.Unscoped().Model(&models.Zone{}).Where("id IN ?", pathIDs(parent.Path)).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		// the paths may have changed while waiting for the locks
+		zone, parent = models.Zone{}, models.Zone{}
+		if err := locked.First(&zone, zoneID).Error; err != nil {
+			return err
+		}
+		if err := locked.First(&parent, parentID).Error; err != nil {
+			return err
+		}
+		if strings.HasPrefix(parent.Path, zone.Path) {
+			return ErrZoneCycle
+		}
+
+		subtree := tx.Model(&models.Zone{}).Where("path LIKE ?", zone.Path+"%")
+		if err := subtree.Count(&moved).Error; err != nil {
+			return err
+		}
+		newPath := fmt.Sprintf("%s%d/", parent.Path, zone.ID)
+		err = tx.Unscoped().Model(&models.Zone{}).Where("path LIKE ?", zone.Path+"%").
+			Updates(map[string]interface{}{
+				"path":  gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", newPath, len(zone.Path)+1),
+				"level": gorm.Expr("level + ?", parent.Level+1-zone.Level),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Zone{}).Where("id = ?", zone.ID).Update("parent_id", parent.ID).Error
+	})
+	return
+}
+
+// pathIDs returns the IDs of the zones a path goes through, root first.
+func pathIDs(path string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.TrimSuffix(path, "/"), "/") {
+		if id, err := strconv.ParseUint(part, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
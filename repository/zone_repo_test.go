@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPathIDs(t *testing.T) {
+	tests := []struct {
+		path string
+		want []uint
+	}{
+		{"", nil},
+		{"7/", []uint{7}},
+		{"1/22/333/", []uint{1, 22, 333}},
+	}
+	for _, tt := range tests {
+		if got := pathIDs(tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("pathIDs(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMoveSubtree(t *testing.T) {
+	db, mock := newMockDB(t)
+	zoneCols := []string{"id", "path", "level", "parent_id"}
+	zone := func() *sqlmock.Rows { return sqlmock.NewRows(zoneCols).AddRow(2, "1/2/", 2, 1) }
+	parent := func() *sqlmock.Rows { return sqlmock.NewRows(zoneCols).AddRow(5, "1/5/", 2, 1) }
+	lockZone := regexp.QuoteMeta("SELECT * FROM `zones` WHERE `zones`.`id` = ? AND `zones`.`deleted_at` IS NULL ORDER BY `zones`.`id` LIMIT ? FOR UPDATE")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockZone).WithArgs(2, 1).WillReturnRows(zone())
+	mock.ExpectQuery(lockZone).WithArgs(5, 1).WillReturnRows(parent())
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `zones` WHERE path LIKE ? FOR UPDATE")).
+		WithArgs("1/2/%").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `zones` WHERE id IN (?,?) FOR UPDATE")).
+		WithArgs(1, 5).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(5))
+	mock.ExpectQuery(lockZone).WithArgs(2, 1).WillReturnRows(zone())
+	mock.ExpectQuery(lockZone).WithArgs(5, 1).WillReturnRows(parent())
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `zones` WHERE path LIKE ? AND `zones`.`deleted_at` IS NULL")).
+		WithArgs("1/2/%").WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(2))
+	// every path keeps what follows the old prefix "1/2/", i.e. from its 5th character
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `zones` SET `level`=level + ?,`path`=CONCAT(?, SUBSTRING(path, ?)),`updated_at`=? WHERE path LIKE ?")).
+		WithArgs(1, "1/5/2/", 5, sqlmock.AnyArg(), "1/2/%").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `zones` SET `parent_id`=?,`updated_at`=? WHERE id = ? AND `zones`.`deleted_at` IS NULL")).
+		WithArgs(5, sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	moved, err := NewZoneRepo(db).MoveSubtree(2, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 2 {
+		t.Errorf("moved = %d, want 2", moved)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMoveSubtreeCycle(t *testing.T) {
+	db, mock := newMockDB(t)
+	zoneCols := []string{"id", "path", "level"}
+	lockZone := regexp.QuoteMeta("SELECT * FROM `zones` WHERE `zones`.`id` = ?")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockZone).WithArgs(2, 1).WillReturnRows(sqlmock.NewRows(zoneCols).AddRow(2, "1/2/", 2))
+	mock.ExpectQuery(lockZone).WithArgs(5, 1).WillReturnRows(sqlmock.NewRows(zoneCols).AddRow(5, "1/5/", 2))
+	mock.ExpectQuery("SELECT `id` FROM `zones`").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT `id` FROM `zones`").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// zone 5 was moved under zone 2 while this move waited for the locks
+	mock.ExpectQuery(lockZone).WithArgs(2, 1).WillReturnRows(sqlmock.NewRows(zoneCols).AddRow(2, "1/2/", 2))
+	mock.ExpectQuery(lockZone).WithArgs(5, 1).WillReturnRows(sqlmock.NewRows(zoneCols).AddRow(5, "1/2/5/", 3))
+	mock.ExpectRollback()
+
+	if _, err := NewZoneRepo(db).MoveSubtree(2, 5); !errors.Is(err, ErrZoneCycle) {
+		t.Fatalf("err = %v, want %v", err, ErrZoneCycle)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
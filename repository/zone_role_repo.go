@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"golang-rest-user/models"
+
+	"gorm.io/gorm"
+)
+
+type ZoneRoleRepo interface {
+	List() ([]models.ZoneRole, error)
+	GetByName(name string) (*models.ZoneRole, error)
+	Create(*models.ZoneRole) error
+	Update(*models.ZoneRole) error
+	Delete(name string) (int64, error)
+}
+
+type zoneRoleRepo struct {
+	db *gorm.DB
+}
+
+func NewZoneRoleRepo(db *gorm.DB) ZoneRoleRepo {
+	return &zoneRoleRepo{db: db}
+}
+
+func (r *zoneRoleRepo) List() (roles []models.ZoneRole, err error) {
+	err = r.db.Order("name ASC").Find(&roles).Error
+	return
+}
+
+func (r *zoneRoleRepo) GetByName(name string) (*models.ZoneRole, error) {
+	var role models.ZoneRole
+	if err := r.db.Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *zoneRoleRepo) Create(role *models.ZoneRole) error {
+	return r.db.Create(role).Error
+}
+
+func (r *zoneRoleRepo) Update(role *models.ZoneRole) error {
+	return r.db.Save(role).Error
+}
+
+// Delete removes the role for good, so its name can be used again.
+func (r *zoneRoleRepo) Delete(name string) (int64, error) {
+	res := r.db.Unscoped().Where("name = ?", name).Delete(&models.ZoneRole{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"errors"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrZoneParentMissing = errors.New("the parent zone no longer exists, restore it first")
+
+type ZoneTrashRepo interface {
+	// Trash soft-deletes the live part of the zone's subtree and its
+	// user_zones rows, and records the zone in the trash.
+	Trash(zone *models.Zone, deletedBy uint) (trashed int64, err error)
+	// List returns the root zones the user owned when they were trashed, and
+	// the entries lying under one of paths.
+	List(ownerID uint, paths []string, page, pageSize int) ([]TrashItem, int64, error)
+	// Get returns the trashed zone with the given UUID, or gorm.ErrRecordNotFound
+	// if it is not at the top of a trash entry.
+	Get(zoneUUID string) (*TrashItem, error)
+	// IsOwner reports whether the user held owner on the zone when it was
+	// trashed, i.e. whether restoring it gives the grant back.
+	IsOwner(item *TrashItem, userID uint) (bool, error)
+	// Restore brings the entry's rows back once check accepts its zones,
+	// ordered by level, under their parent as it is now. It fails with
+	// ErrZoneParentMissing if the parent is not live, and with
+	// gorm.ErrRecordNotFound if the entry is gone.
+	Restore(item *TrashItem, check func(zones []models.Zone, parent *models.Zone) error) (restored int64, err error)
+	// Purge fails with gorm.ErrRecordNotFound if the entry is gone.
+	Purge(item *TrashItem) (purged int64, err error)
+	ListExpired(before time.Time) ([]TrashItem, error)
+}
+
+// TrashItem is a trash entry with its zone. Zones counts the zones that
+// restoring it brings back; Restorable is false once the parent is gone.
+type TrashItem struct {
+	TrashID    uint
+	ZoneID     uint
+	UUID       string
+	Name       string
+	Type       string
+	Path       string
+	ParentID   *uint
+	DeletedBy  *uint
+	TrashedAt  time.Time
+	Zones      int64
+	Restorable bool
+}
+
+type zoneTrashRepo struct {
+	db *gorm.DB
+}
+
+func NewZoneTrashRepo(db *gorm.DB) ZoneTrashRepo {
+	return &zoneTrashRepo{db: db}
+}
+
+func (r *zoneTrashRepo) Trash(zone *models.Zone, deletedBy uint) (trashed int64, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		entry := models.TrashedZone{ZoneID: zone.ID, DeletedBy: &deletedBy, TrashedAt: time.Now()}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		trash := map[string]interface{}{"deleted_at": entry.TrashedAt, "trash_id": entry.ID}
+		res := tx.Model(&models.Zone{}).Where("path LIKE ?", zone.Path+"%").Updates(trash)
+		if res.Error != nil {
+			return res.Error
+		}
+		trashed = res.RowsAffected
+		return tx.Model(&models.UserZone{}).
+			Where("zone_id IN (?)", tx.Unscoped().Model(&models.Zone{}).Select("id").Where("trash_id = ?", entry.ID)).
+			Updates(trash).Error
+	})
+	return
+}
+
+func (r *zoneTrashRepo) entries() *gorm.DB {
+	return r.db.Table("trashed_zones t").Joins("JOIN zones z ON z.id = t.zone_id")
+}
+
+func (r *zoneTrashRepo) items() *gorm.DB {
+	return r.entries().
+		Select(`t.id AS trash_id, z.id AS zone_id, z.uuid, z.name, z.type, z.path, z.parent_id, t.deleted_by, t.trashed_at,
+			(SELECT COUNT(*) FROM zones d WHERE d.trash_id = t.id) AS zones,
+			(z.parent_id IS NULL OR p.id IS NOT NULL) AS restorable`).
+		Joins("LEFT JOIN zones p ON p.id = z.parent_id AND p.deleted_at IS NULL")
+}
+
+func (r *zoneTrashRepo) List(ownerID uint, paths []string, page, pageSize int) (items []TrashItem, total int64, err error) {
+	visible := r.db.Where(`z.parent_id IS NULL AND EXISTS (SELECT 1 FROM user_zones uz
+		WHERE uz.zone_id = z.id AND uz.user_id = ? AND uz.permission = ? AND uz.trash_id = t.id)`,
+		ownerID, enums.UserOwner)
+	for _, path := range paths {
+		visible = visible.Or("z.path LIKE ?", path+"%")
+	}
+	if err = r.entries().Where(visible).Count(&total).Error; err != nil {
+		return
+	}
+	err = r.items().Where(visible).
+		Order("t.trashed_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&items).Error
+	return
+}
+
+func (r *zoneTrashRepo) Get(zoneUUID string) (*TrashItem, error) {
+	var item TrashItem
+	res := r.items().Where("z.uuid = ?", zoneUUID).Limit(1).Scan(&item)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (r *zoneTrashRepo) IsOwner(item *TrashItem, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.UserZone{}).
+		Where("zone_id = ? AND user_id = ? AND permission = ? AND trash_id = ?",
+			item.ZoneID, userID, enums.UserOwner, item.TrashID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Restore locks the entry, so it is restored or purged once, and the live
+// parent, so it cannot be trashed while its child comes back.
+func (r *zoneTrashRepo) Restore(item *TrashItem, check func(zones []models.Zone, parent *models.Zone) error) (restored int64, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEntry(tx, item); err != nil {
+			return err
+		}
+		var parent *models.Zone
+		if item.ParentID != nil {
+			parent = &models.Zone{}
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(parent, *item.ParentID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrZoneParentMissing
+			}
+			if err != nil {
+				return err
+			}
+		}
+		var zones []models.Zone
+		err := tx.Unscoped().Where("trash_id = ?", item.TrashID).Order("level ASC, id ASC").Find(&zones).Error
+		if err != nil {
+			return err
+		}
+		if err := check(zones, parent); err != nil {
+			return err
+		}
+		restore := map[string]interface{}{"deleted_at": nil, "trash_id": nil}
+		err = tx.Unscoped().Model(&models.UserZone{}).Where("trash_id = ?", item.TrashID).Updates(restore).Error
+		if err != nil {
+			return err
+		}
+		res := tx.Unscoped().Model(&models.Zone{}).Where("trash_id = ?", item.TrashID).Updates(restore)
+		if res.Error != nil {
+			return res.Error
+		}
+		restored = res.RowsAffected
+		return tx.Delete(&models.TrashedZone{}, item.TrashID).Error
+	})
+	return
+}
+
+// Purge deletes the subtree for good, together with any entries trashed
+// earlier inside it, which could never be restored afterwards. Live zones
+// under the path, if any, are left alone.
+func (r *zoneTrashRepo) Purge(item *TrashItem) (purged int64, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEntry(tx, item); err != nil {
+			return err
+		}
+		var ids []uint
+		err := tx.Unscoped().Model(&models.Zone{}).
+			Where("path LIKE ? AND deleted_at IS NOT NULL", item.Path+"%").Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Unscoped().Where("zone_id IN ?", ids).Delete(&models.UserZone{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("zone_id IN ?", ids).Delete(&models.TrashedZone{}).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&models.Zone{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return
+}
+
+// lockEntry locks the item's trash entry until tx ends, or returns
+// gorm.ErrRecordNotFound if it has been restored or purged meanwhile.
+func lockEntry(tx *gorm.DB, item *TrashItem) error {
+	var entry models.TrashedZone
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, item.TrashID).Error
+}
+
+func (r *zoneTrashRepo) ListExpired(before time.Time) (items []TrashItem, err error) {
+	err = r.items().Where("t.trashed_at < ?", before).Order("t.trashed_at ASC").Scan(&items).Error
+	return
+}
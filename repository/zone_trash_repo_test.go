@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"golang-rest-user/models"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/gorm"
+)
+
+func TestTrashKeysRowsToTheEntry(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `trashed_zones` (`zone_id`,`deleted_by`,`trashed_at`) VALUES (?,?,?)")).
+		WithArgs(2, 7, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(42, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `zones` SET `deleted_at`=?,`trash_id`=?,`updated_at`=? WHERE path LIKE ? AND `zones`.`deleted_at` IS NULL")).
+		WithArgs(sqlmock.AnyArg(), 42, sqlmock.AnyArg(), "1/2/%").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `user_zones` SET `deleted_at`=?,`trash_id`=?,`updated_at`=? WHERE zone_id IN (SELECT `id` FROM `zones` WHERE trash_id = ?) AND `user_zones`.`deleted_at` IS NULL")).
+		WithArgs(sqlmock.AnyArg(), 42, sqlmock.AnyArg(), 42).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	zone := &models.Zone{Path: "1/2/"}
+	zone.ID = 2
+	trashed, err := NewZoneTrashRepo(db).Trash(zone, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trashed != 2 {
+		t.Errorf("trashed = %d, want 2", trashed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const (
+	selectEntry  = "SELECT * FROM `trashed_zones` WHERE `trashed_zones`.`id` = ? ORDER BY `trashed_zones`.`id` LIMIT ? FOR UPDATE"
+	selectParent = "SELECT * FROM `zones` WHERE `zones`.`id` = ? AND `zones`.`deleted_at` IS NULL ORDER BY `zones`.`id` LIMIT ? FOR UPDATE"
+	selectZones  = "SELECT * FROM `zones` WHERE trash_id = ? ORDER BY level ASC, id ASC"
+)
+
+func acceptZones([]models.Zone, *models.Zone) error { return nil }
+
+func trashItem() *TrashItem {
+	parentID := uint(1)
+	return &TrashItem{TrashID: 42, ZoneID: 2, Path: "1/2/", ParentID: &parentID}
+}
+
+func TestRestore(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(selectEntry)).WithArgs(42, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "zone_id"}).AddRow(42, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(selectParent)).WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path"}).AddRow(1, "1/"))
+	mock.ExpectQuery(regexp.QuoteMeta(selectZones)).WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path"}).AddRow(2, "1/2/").AddRow(3, "1/2/3/"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `user_zones` SET `deleted_at`=?,`trash_id`=?,`updated_at`=? WHERE trash_id = ?")).
+		WithArgs(nil, nil, sqlmock.AnyArg(), 42).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `zones` SET `deleted_at`=?,`trash_id`=?,`updated_at`=? WHERE trash_id = ?")).
+		WithArgs(nil, nil, sqlmock.AnyArg(), 42).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `trashed_zones` WHERE `trashed_zones`.`id` = ?")).
+		WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var checked []string
+	restored, err := NewZoneTrashRepo(db).Restore(trashItem(), func(zones []models.Zone, parent *models.Zone) error {
+		checked = append(checked, parent.Path)
+		for _, zone := range zones {
+			checked = append(checked, zone.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1/", "1/2/", "1/2/3/"}; !slices.Equal(checked, want) {
+		t.Errorf("checked parent and zones = %v, want %v", checked, want)
+	}
+	if restored != 2 {
+		t.Errorf("restored = %d, want 2", restored)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreChecksTheParentUnderLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		parent  *sqlmock.Rows
+		entry   *sqlmock.Rows
+		wantErr error
+	}{
+		{
+			name:    "parent trashed after the entry was loaded",
+			entry:   sqlmock.NewRows([]string{"id"}).AddRow(42),
+			parent:  sqlmock.NewRows([]string{"id"}),
+			wantErr: ErrZoneParentMissing,
+		},
+		{
+			name:    "entry restored or purged meanwhile",
+			entry:   sqlmock.NewRows([]string{"id"}),
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(selectEntry)).WithArgs(42, 1).WillReturnRows(tt.entry)
+			if tt.parent != nil {
+				mock.ExpectQuery(regexp.QuoteMeta(selectParent)).WithArgs(1, 1).WillReturnRows(tt.parent)
+			}
+			mock.ExpectRollback()
+
+			if _, err := NewZoneTrashRepo(db).Restore(trashItem(), acceptZones); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRestoreRollsBackWhenTheCheckFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(selectEntry)).WithArgs(42, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(regexp.QuoteMeta(selectParent)).WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(selectZones)).WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectRollback()
+
+	rejected := errors.New("rejected")
+	_, err := NewZoneTrashRepo(db).Restore(trashItem(), func([]models.Zone, *models.Zone) error { return rejected })
+	if !errors.Is(err, rejected) {
+		t.Fatalf("err = %v, want %v", err, rejected)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeOnlyDeletesTrashedRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(selectEntry)).WithArgs(42, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `zones` WHERE path LIKE ? AND deleted_at IS NOT NULL")).
+		WithArgs("1/2/%").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `user_zones` WHERE zone_id IN (?,?)")).
+		WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `trashed_zones` WHERE zone_id IN (?,?)")).
+		WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `zones` WHERE id IN (?,?)")).
+		WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	purged, err := NewZoneTrashRepo(db).Purge(trashItem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("purged = %d, want 2", purged)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"golang-rest-user/models"
+
+	"gorm.io/gorm"
+)
+
+type ZoneTypeRepo interface {
+	List() ([]models.ZoneType, error)
+	GetByName(name string) (*models.ZoneType, error)
+	Create(*models.ZoneType) error
+	Update(*models.ZoneType) error
+	Delete(name string) (int64, error)
+}
+
+type zoneTypeRepo struct {
+	db *gorm.DB
+}
+
+func NewZoneTypeRepo(db *gorm.DB) ZoneTypeRepo {
+	return &zoneTypeRepo{db: db}
+}
+
+func (r *zoneTypeRepo) List() (types []models.ZoneType, err error) {
+	err = r.db.Order("name ASC").Find(&types).Error
+	return
+}
+
+func (r *zoneTypeRepo) GetByName(name string) (*models.ZoneType, error) {
+	var zoneType models.ZoneType
+	if err := r.db.Where("name = ?", name).First(&zoneType).Error; err != nil {
+		return nil, err
+	}
+	return &zoneType, nil
+}
+
+func (r *zoneTypeRepo) Create(zoneType *models.ZoneType) error {
+	return r.db.Create(zoneType).Error
+}
+
+func (r *zoneTypeRepo) Update(zoneType *models.ZoneType) error {
+	return r.db.Save(zoneType).Error
+}
+
+// Delete removes the type for good, so its name can be used again.
+func (r *zoneTypeRepo) Delete(name string) (int64, error) {
+	res := r.db.Unscoped().Where("name = ?", name).Delete(&models.ZoneType{})
+	return res.RowsAffected, res.Error
+}
@@ -1,8 +1,24 @@
 package response
 
 const (
-	CodeSuccess    = "SUS0000"
-	CodeBadRequest = "ERR0001"
+	CodeSuccess       = "SUS0000"
+	CodeBadRequest    = "ERR0001"
+	CodeUnauthorized  = "ERR0002"
+	CodeForbidden     = "ERR0003"
+	CodeInternalError = "ERR0008"
+
+	CodeTooManyAttempts  = "ERR0004"
+	CodeAccountLocked    = "ERR0005"
+	CodeLoginUnavailable = "ERR0006"
+	CodeEmailNotVerified = "ERR0007"
+
+	CodeTenantSuspended   = "ERR0101"
+	CodeTenantUnavailable = "ERR0102"
+	CodeTenantNotFound    = "ERR0103"
+
+	CodeZoneForbidden = "ERR0201"
+	CodeZoneNotFound  = "ERR0202"
+	CodeZoneInvalid   = "ERR0203"
 )
 
 const (
@@ -17,6 +17,17 @@ func Success(c *gin.Context, data interface{}) {
 	})
 }
 
+func Accepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, BaseResponse{
+		Code:       CodeSuccess,
+		DebugStack: nil,
+		Message:    MsgSuccess,
+		RequestID:  c.GetString("request_id"),
+		Response:   data,
+		Version:    "2022.11.15.20:44",
+	})
+}
+
 func Error(c *gin.Context, code string, msg string, data interface{}, httpStatus int) {
 	c.JSON(httpStatus, BaseResponse{
 		Code:       code,
@@ -1,41 +1,128 @@
 package routes
 
 import (
+	"golang-rest-user/enums"
 	"golang-rest-user/handler"
 	"golang-rest-user/handler/tenant"
+	"golang-rest-user/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func TenantRoutes(r *gin.RouterGroup) {
-	r.GET("", handler.ListTenant)            // GET /api/v1/tenants
-	r.POST("", handler.CreateTenant)         // POST /api/v1/tenants
-	r.GET("/:code", handler.GetByTenantCode) // GET /api/v1/tenants/:code
-	r.PUT("/:code", handler.UpdateTenant)    // PUT /api/v1/tenants/:code
-	r.DELETE("/:code", handler.DeleteTenant) // DELETE /api/v1/tenants/:code
+	admin := middleware.RequireOperatorRole(enums.OperatorRoleAdmin)
+
+	r.GET("", handler.ListTenant)                                 // GET /api/v1/tenants
+	r.POST("", admin, handler.CreateTenant)                       // POST /api/v1/tenants
+	r.GET("/:code", handler.GetByTenantCode)                      // GET /api/v1/tenants/:code
+	r.GET("/:code/state", handler.GetTenantState)                 // GET /api/v1/tenants/:code/state
+	r.PUT("/:code", admin, handler.UpdateTenant)                  // PUT /api/v1/tenants/:code
+	r.DELETE("/:code", admin, handler.DeleteTenant)               // DELETE /api/v1/tenants/:code
+	r.POST("/:code/suspend", admin, handler.SuspendTenant)        // POST /api/v1/tenants/:code/suspend
+	r.POST("/:code/resume", admin, handler.ResumeTenant)          // POST /api/v1/tenants/:code/resume
+	r.GET("/:code/settings", handler.GetTenantSettings)           // GET /api/v1/tenants/:code/settings
+	r.PUT("/:code/settings", admin, handler.UpdateTenantSettings) // PUT /api/v1/tenants/:code/settings
+
+	r.GET("/schema-versions", admin, handler.GetSchemaVersions)           // GET /api/v1/tenants/schema-versions
+	r.GET("/provisioning/:uuid", handler.GetProvisioning)                 // GET /api/v1/tenants/provisioning/:uuid
+	r.POST("/provisioning/:uuid/retry", admin, handler.RetryProvisioning) // POST /api/v1/tenants/provisioning/:uuid/retry
+}
+
+func PlatformAuthRoutes(r *gin.RouterGroup) {
+	r.POST("/login", handler.OperatorLogin)     // POST /api/v1/platform/auth/login
+	r.POST("/refresh", handler.OperatorRefresh) // POST /api/v1/platform/auth/refresh
+	r.POST("/logout", handler.OperatorLogout)   // POST /api/v1/platform/auth/logout
+}
+
+func OperatorRoutes(r *gin.RouterGroup) {
+	r.Use(middleware.RequireOperatorRole(enums.OperatorRoleAdmin))
+
+	r.GET("", handler.ListOperators)           // GET /api/v1/platform/operators
+	r.POST("", handler.CreateOperator)         // POST /api/v1/platform/operators
+	r.PUT("/:uuid", handler.UpdateOperator)    // PUT /api/v1/platform/operators/:uuid
+	r.DELETE("/:uuid", handler.DeleteOperator) // DELETE /api/v1/platform/operators/:uuid
 }
 
 func UserRoutes(r *gin.RouterGroup) {
-	r.GET("", tenant.ListUsers)           // GET /api/v1/users
-	r.POST("", tenant.CreateUser)         // POST /api/v1/users
-	r.DELETE("", tenant.DeleteManyUsers)  // DELETE /api/v1/users?uuids=1b0f0fe4-8710-4518-b8bc-7f1e52b280e4,1c8edc4f-b1a0-4252-808b-682eb76551ad,...
-	r.GET("/:uuid", tenant.GetByUserUUID) // GET /api/v1/users/:uuid
-	r.PUT("/:uuid", tenant.UpdateUser)    // PUT /api/v1/users/:uuid
+	admin := middleware.RequireTenantRole(enums.TenantRoleAdmin)
+
+	r.GET("", tenant.ListUsers)                        // GET /api/v1/users
+	r.POST("", admin, tenant.CreateUser)               // POST /api/v1/users
+	r.DELETE("", admin, tenant.DeleteManyUsers)        // DELETE /api/v1/users?uuids=1b0f0fe4-8710-4518-b8bc-7f1e52b280e4,1c8edc4f-b1a0-4252-808b-682eb76551ad,...
+	r.GET("/:uuid", tenant.GetByUserUUID)              // GET /api/v1/users/:uuid
+	r.PUT("/:uuid", admin, tenant.UpdateUser)          // PUT /api/v1/users/:uuid
+	r.PUT("/:uuid/role", admin, tenant.UpdateUserRole) // PUT /api/v1/users/:uuid/role
+	r.POST("/:uuid/unlock", admin, tenant.UnlockUser)  // POST /api/v1/users/:uuid/unlock
+	r.DELETE("/:uuid/mfa", admin, tenant.ResetUserMFA) // DELETE /api/v1/users/:uuid/mfa
 }
 
 func AuthRoutes(r *gin.RouterGroup) {
-	r.POST("/register", tenant.Register) // POST /api/v1/auth/register
-	r.POST("/login", tenant.Login)       // POST /api/v1/auth/login
-	r.POST("/logout", tenant.Logout)     // POST /api/v1/auth/logout
-	r.POST("/refresh", tenant.Refresh)   // POST /api/v1/auth/refresh
+	r.POST("/register", tenant.Register)                      // POST /api/v1/auth/register
+	r.POST("/login", tenant.Login)                            // POST /api/v1/auth/login
+	r.POST("/logout", tenant.Logout)                          // POST /api/v1/auth/logout
+	r.POST("/refresh", tenant.Refresh)                        // POST /api/v1/auth/refresh
+	r.POST("/password/forgot", tenant.ForgotPassword)         // POST /api/v1/auth/password/forgot
+	r.POST("/password/reset", tenant.ResetPassword)           // POST /api/v1/auth/password/reset
+	r.POST("/verify-email", tenant.VerifyEmail)               // POST /api/v1/auth/verify-email
+	r.POST("/verify-email/resend", tenant.ResendVerification) // POST /api/v1/auth/verify-email/resend
+	r.POST("/mfa/verify", tenant.VerifyMFA)                   // POST /api/v1/auth/mfa/verify
+	r.POST("/mfa/enroll", tenant.EnrollMFAForLogin)           // POST /api/v1/auth/mfa/enroll
+}
+
+func MeRoutes(r *gin.RouterGroup) {
+	r.GET("", tenant.GetMe)                     // GET /api/v1/me
+	r.PUT("", tenant.UpdateMe)                  // PUT /api/v1/me
+	r.PUT("/password", tenant.ChangeMyPassword) // PUT /api/v1/me/password
+	r.GET("/zones", tenant.GetMyZones)          // GET /api/v1/me/zones
+}
+
+func MFARoutes(r *gin.RouterGroup) {
+	r.GET("", tenant.GetMFAStatus)                            // GET /api/v1/mfa
+	r.POST("/enroll", tenant.EnrollMFA)                       // POST /api/v1/mfa/enroll
+	r.POST("/confirm", tenant.ConfirmMFA)                     // POST /api/v1/mfa/confirm
+	r.POST("/disable", tenant.DisableMFA)                     // POST /api/v1/mfa/disable
+	r.POST("/recovery-codes", tenant.RegenerateRecoveryCodes) // POST /api/v1/mfa/recovery-codes
+}
+
+func SessionRoutes(r *gin.RouterGroup) {
+	r.GET("", tenant.ListSessions)           // GET /api/v1/sessions
+	r.DELETE("", tenant.RevokeOtherSessions) // DELETE /api/v1/sessions
+	r.DELETE("/:id", tenant.RevokeSession)   // DELETE /api/v1/sessions/:id
 }
 
 func ZonesRoutes(r *gin.RouterGroup) {
-	r.GET("", tenant.ListZones)                     // GET /api/v1/zones
-	r.GET("/share-with-me", tenant.ListSharedZones) // GET /api/v1/zones/share-with-me
-	r.POST("", tenant.CreateZone)                   // POST /api/v1/zones
-	r.PUT("/:uuid", tenant.UpdateZone)              // PUT /api/v1/zones/:uuid
-	r.DELETE("/:uuid", tenant.DeleteZone)           // DELETE /api/v1/zones/:uuid
+	r.GET("", tenant.ListZones)                        // GET /api/v1/zones
+	r.GET("/share-with-me", tenant.ListSharedZones)    // GET /api/v1/zones/share-with-me
+	r.POST("", tenant.CreateZone)                      // POST /api/v1/zones
+	r.GET("/trash", tenant.ListZoneTrash)              // GET /api/v1/zones/trash
+	r.POST("/trash/:uuid/restore", tenant.RestoreZone) // POST /api/v1/zones/trash/:uuid/restore
+	r.DELETE("/trash/:uuid", tenant.PurgeZone)         // DELETE /api/v1/zones/trash/:uuid
+	r.GET("/:uuid", tenant.GetZone)                    // GET /api/v1/zones/:uuid?breadcrumb=true
+	r.GET("/:uuid/ancestors", tenant.GetZoneAncestors) // GET /api/v1/zones/:uuid/ancestors
+	r.GET("/:uuid/tree", tenant.GetZoneTree)           // GET /api/v1/zones/:uuid/tree?depth=3&include_metadata=true&fields=name,type&lazy=true
+	r.PUT("/:uuid", tenant.UpdateZone)                 // PUT /api/v1/zones/:uuid
+	r.POST("/:uuid/move", tenant.MoveZone)             // POST /api/v1/zones/:uuid/move
+	r.DELETE("/:uuid", tenant.DeleteZone)              // DELETE /api/v1/zones/:uuid
+}
+
+func ZoneRoleRoutes(r *gin.RouterGroup) {
+	admin := middleware.RequireTenantRole(enums.TenantRoleAdmin)
+
+	r.GET("", tenant.ListZoneRoles)                  // GET /api/v1/zone-roles
+	r.POST("", admin, tenant.CreateZoneRole)         // POST /api/v1/zone-roles
+	r.PUT("/:name", admin, tenant.UpdateZoneRole)    // PUT /api/v1/zone-roles/:name
+	r.DELETE("/:name", admin, tenant.DeleteZoneRole) // DELETE /api/v1/zone-roles/:name
+}
+
+func ZoneTypeRoutes(r *gin.RouterGroup) {
+	admin := middleware.RequireTenantRole(enums.TenantRoleAdmin)
+
+	r.GET("", tenant.ListZoneTypes)                      // GET /api/v1/zone-types
+	r.GET("/enforcement", tenant.GetZoneTypeEnforcement) // GET /api/v1/zone-types/enforcement
+	r.GET("/:name", tenant.GetZoneType)                  // GET /api/v1/zone-types/:name
+	r.POST("", admin, tenant.CreateZoneType)             // POST /api/v1/zone-types
+	r.PUT("/:name", admin, tenant.UpdateZoneType)        // PUT /api/v1/zone-types/:name
+	r.DELETE("/:name", admin, tenant.DeleteZoneType)     // DELETE /api/v1/zone-types/:name
 }
 
 func ShareRoutes(r *gin.RouterGroup) {
@@ -7,11 +7,15 @@ import (
 )
 
 type Claims struct {
-	Username   string          `json:"username"`
-	UserID     uint            `json:"user_id"`
-	TenantCode string          `json:"tenant_code"`
-	Version    int             `json:"ver"`
-	Type       enums.TokenType `json:"type"`
+	Username   string             `json:"username"`
+	UserID     uint               `json:"user_id"`
+	TenantCode string             `json:"tenant_code"`
+	SessionID  string             `json:"sid,omitempty"`
+	Version    int                `json:"ver"`
+	Type       enums.TokenType    `json:"type"`
+	Role       enums.OperatorRole `json:"role,omitempty"`
+	// UserRole is the tenant role of a tenant user, see AuthMiddleware.
+	UserRole enums.TenantRole `json:"user_role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -6,17 +6,31 @@ import (
 )
 
 type JWTConfig struct {
-	SecretKey       []byte
-	AccessTokenTTL  time.Duration
-	RefreshTokenTTL time.Duration
-	Issuer          string
+	// SecretKey signs HS256 tokens. With an asymmetric Algorithm it is only
+	// used to verify HS256 tokens issued before the switch; unset it once
+	// those have expired.
+	SecretKey         []byte
+	Algorithm         string
+	KeyReloadInterval time.Duration
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	Issuer            string
 }
 
 func LoadJWTConfig() *JWTConfig {
-	return &JWTConfig{
-		SecretKey:       []byte(os.Getenv("JWT_SECRET_KEY")),
-		AccessTokenTTL:  time.Minute * 15,
-		RefreshTokenTTL: time.Hour * 24 * 7,
-		Issuer:          "golang-rest-user",
+	cfg := &JWTConfig{
+		SecretKey:         []byte(os.Getenv("JWT_SECRET_KEY")),
+		Algorithm:         os.Getenv("JWT_SIGNING_ALGORITHM"),
+		KeyReloadInterval: time.Minute,
+		AccessTokenTTL:    time.Minute * 15,
+		RefreshTokenTTL:   time.Hour * 24 * 7,
+		Issuer:            "golang-rest-user",
 	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = AlgorithmHS256
+	}
+	if d, err := time.ParseDuration(os.Getenv("JWT_KEY_RELOAD_INTERVAL")); err == nil && d > 0 {
+		cfg.KeyReloadInterval = d
+	}
+	return cfg
 }
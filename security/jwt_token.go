@@ -2,38 +2,130 @@ package security
 
 import (
 	"golang-rest-user/enums"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// Manager signs tokens with HS256 and JWT_SECRET_KEY, or with the active key
+// of the ring for asymmetric algorithms. Ring tokens carry the key's kid, so
+// retired keys keep verifying the tokens they issued.
 type Manager struct {
 	jwtConfig *JWTConfig
+	store     KeyStore
+
+	mu       sync.RWMutex
+	ring     *KeyRing
+	loadedAt time.Time
 }
 
-func NewManager(jwtConfig *JWTConfig) *Manager {
-	return &Manager{jwtConfig: jwtConfig}
+// minReload throttles reloads triggered by tokens with an unknown kid.
+const minReload = 5 * time.Second
+
+func NewManager(jwtConfig *JWTConfig, store KeyStore) *Manager {
+	return &Manager{jwtConfig: jwtConfig, store: store, ring: NewKeyRing(nil)}
 }
 
-func (m *Manager) GenerateToken(userID uint, username, tenantCode string, tokenType enums.TokenType, ttl, ver int) (*TokenResult, error) {
-	jti, _ := uuid.NewUUID()
+func (m *Manager) Algorithm() string {
+	return m.jwtConfig.Algorithm
+}
+
+// Reload replaces the ring with the keys currently in the store.
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reloadLocked()
+}
+
+func (m *Manager) reloadLocked() error {
+	m.loadedAt = time.Now()
+	if m.store == nil {
+		return nil
+	}
+	keys, err := m.store.LoadKeys()
+	if err != nil {
+		return err
+	}
+	m.ring = NewKeyRing(keys)
+	return nil
+}
+
+// keyRing returns the ring, reloading it first when it is older than maxAge.
+func (m *Manager) keyRing(maxAge time.Duration) *KeyRing {
+	m.mu.RLock()
+	ring, stale := m.ring, time.Since(m.loadedAt) > maxAge
+	m.mu.RUnlock()
+	if !stale {
+		return ring
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Since(m.loadedAt) > maxAge {
+		if err := m.reloadLocked(); err != nil {
+			log.Println("reload signing keys:", err)
+		}
+	}
+	return m.ring
+}
+
+func (m *Manager) JWKS() *JWKSet {
+	return m.keyRing(m.jwtConfig.KeyReloadInterval).JWKS()
+}
+
+// GenerateToken issues a tenant user token bound to the session (refresh
+// token family) sessionID.
+func (m *Manager) GenerateToken(userID uint, username, tenantCode, sessionID string, role enums.TenantRole, tokenType enums.TokenType, ttl, ver int) (*TokenResult, error) {
 	claims := &Claims{
 		Username:   username,
 		UserID:     userID,
 		TenantCode: tenantCode,
+		SessionID:  sessionID,
+		UserRole:   role,
 		Type:       tokenType,
 		Version:    ver,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    m.jwtConfig.Issuer,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(ttl) * time.Second)),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        jti.String(),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(m.jwtConfig.SecretKey)
+	}
+	return m.sign(claims, ttl)
+}
+
+// GenerateOperatorToken issues a platform operator token. It carries no
+// tenant code, so it can never pass the tenant AuthMiddleware.
+func (m *Manager) GenerateOperatorToken(operatorID uint, username string, role enums.OperatorRole, tokenType enums.TokenType, ttl, ver int) (*TokenResult, error) {
+	claims := &Claims{
+		Username: username,
+		UserID:   operatorID,
+		Role:     role,
+		Type:     tokenType,
+		Version:  ver,
+	}
+	return m.sign(claims, ttl)
+}
+
+func (m *Manager) sign(claims *Claims, ttl int) (*TokenResult, error) {
+	jti, _ := uuid.NewUUID()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    m.jwtConfig.Issuer,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(ttl) * time.Second)),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        jti.String(),
+	}
+	var signed string
+	var err error
+	if IsAsymmetric(m.jwtConfig.Algorithm) {
+		key := m.keyRing(m.jwtConfig.KeyReloadInterval).Active()
+		if key == nil {
+			return nil, ErrNoSigningKey
+		}
+		token := jwt.NewWithClaims(key.method(), claims)
+		token.Header["kid"] = key.ID
+		signed, err = token.SignedString(key.Private)
+	} else {
+		signed, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.jwtConfig.SecretKey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -44,9 +136,9 @@ func (m *Manager) GenerateToken(userID uint, username, tenantCode string, tokenT
 }
 
 func (m *Manager) ParseToken(tokenStr string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return m.jwtConfig.SecretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, m.verificationKey,
+		jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA}),
+	)
 	if err != nil || !token.Valid {
 		return nil, err
 	}
@@ -55,3 +147,28 @@ func (m *Manager) ParseToken(tokenStr string) (*Claims, error) {
 	}
 	return nil, jwt.ErrTokenInvalidClaims
 }
+
+// verificationKey picks the key by kid and pins the algorithm to the one the
+// key was created for. Tokens without a kid are HS256 tokens.
+func (m *Manager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if token.Method.Alg() != AlgorithmHS256 || len(m.jwtConfig.SecretKey) == 0 {
+			return nil, ErrUnknownKey
+		}
+		return m.jwtConfig.SecretKey, nil
+	}
+
+	key := m.keyRing(m.jwtConfig.KeyReloadInterval).Lookup(kid)
+	if key == nil {
+		// signed by a key another instance created after our last reload
+		key = m.keyRing(minReload).Lookup(kid)
+	}
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.Public, nil
+}
@@ -0,0 +1,41 @@
+package security
+
+// KeyStore loads the signing keys: the active key with its private half and
+// the retired keys that still verify unexpired tokens.
+type KeyStore interface {
+	LoadKeys() ([]*SigningKey, error)
+}
+
+// KeyRing is an immutable snapshot of the keys; the Manager swaps in a new
+// ring on reload.
+type KeyRing struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeyRing(keys []*SigningKey) *KeyRing {
+	ring := &KeyRing{keys: make(map[string]*SigningKey, len(keys))}
+	for _, k := range keys {
+		ring.keys[k.ID] = k
+		if k.Active && k.Private != nil && (ring.active == nil || k.CreatedAt.After(ring.active.CreatedAt)) {
+			ring.active = k
+		}
+	}
+	return ring
+}
+
+func (r *KeyRing) Active() *SigningKey {
+	return r.active
+}
+
+func (r *KeyRing) Lookup(kid string) *SigningKey {
+	return r.keys[kid]
+}
+
+func (r *KeyRing) Keys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		keys = append(keys, k)
+	}
+	return keys
+}
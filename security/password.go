@@ -0,0 +1,218 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang-rest-user/utils"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher hashes passwords into a self-describing string that carries
+// the algorithm and its parameters, so stored hashes survive config changes.
+type PasswordHasher interface {
+	// Algorithm is the name used in PASSWORD_HASH_ALGORITHM.
+	Algorithm() string
+	Hash(password string) (string, error)
+	// Match reports whether the encoded string was produced by this hasher.
+	Match(encoded string) bool
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash reports whether encoded uses weaker parameters than the
+	// hasher is currently configured with.
+	NeedsRehash(encoded string) bool
+}
+
+type argon2idHasher struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	saltLen uint32
+	keyLen  uint32
+}
+
+func NewArgon2idHasher(memory, time uint32, threads uint8) PasswordHasher {
+	return &argon2idHasher{
+		memory:  memory,
+		time:    time,
+		threads: threads,
+		saltLen: 16,
+		keyLen:  32,
+	}
+}
+
+func (h *argon2idHasher) Algorithm() string {
+	return "argon2id"
+}
+
+// Hash returns the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.time, h.memory, h.threads, h.keyLen)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.memory,
+		h.time,
+		h.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Match(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+type argon2idParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func decodeArgon2id(encoded string) (*argon2idParams, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHashFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrUnknownHashFormat
+	}
+	p := &argon2idParams{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return nil, ErrUnknownHashFormat
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownHashFormat
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, ErrUnknownHashFormat
+	}
+	return p, nil
+}
+
+func (h *argon2idHasher) Verify(encoded, password string) (bool, error) {
+	p, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encoded string) bool {
+	p, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return p.memory < h.memory || p.time < h.time || p.threads < h.threads || uint32(len(p.key)) < h.keyLen
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Algorithm() string {
+	return "bcrypt"
+}
+
+// Hash returns the modular crypt format, e.g. $2a$12$<salt+hash>
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (h *bcryptHasher) Match(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *bcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < h.cost
+}
+
+// PasswordManager hashes new passwords with the configured default hasher and
+// verifies any format it knows about, including the legacy AES-GCM format
+// that was used before passwords were hashed.
+type PasswordManager struct {
+	defaultHasher PasswordHasher
+	hashers       []PasswordHasher
+}
+
+func NewPasswordManager(defaultHasher PasswordHasher, others ...PasswordHasher) *PasswordManager {
+	return &PasswordManager{
+		defaultHasher: defaultHasher,
+		hashers:       append([]PasswordHasher{defaultHasher}, others...),
+	}
+}
+
+func (m *PasswordManager) Hash(password string) (string, error) {
+	return m.defaultHasher.Hash(password)
+}
+
+// IsLegacy reports whether encoded is an AES-GCM ciphertext rather than a hash.
+// Every supported hash format starts with '$', base64 ciphertext never does.
+func IsLegacy(encoded string) bool {
+	return !strings.HasPrefix(encoded, "$")
+}
+
+// Verify checks password against encoded. needsRehash is only meaningful when
+// ok is true and tells the caller to store a fresh hash from Hash.
+func (m *PasswordManager) Verify(encoded, password string) (ok bool, needsRehash bool, err error) {
+	if IsLegacy(encoded) {
+		decrypted, err := utils.AESGCMDecrypt(encoded)
+		if err != nil {
+			return false, false, err
+		}
+		ok = subtle.ConstantTimeCompare([]byte(decrypted), []byte(password)) == 1
+		return ok, ok, nil
+	}
+	for _, h := range m.hashers {
+		if !h.Match(encoded) {
+			continue
+		}
+		ok, err = h.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		if h != m.defaultHasher {
+			return true, true, nil
+		}
+		return true, h.NeedsRehash(encoded), nil
+	}
+	return false, false, ErrUnknownHashFormat
+}
@@ -0,0 +1,51 @@
+package security
+
+import (
+	"os"
+	"strconv"
+)
+
+type PasswordConfig struct {
+	Algorithm     string
+	BcryptCost    int
+	Argon2Memory  uint32
+	Argon2Time    uint32
+	Argon2Threads uint8
+}
+
+func LoadPasswordConfig() *PasswordConfig {
+	cfg := &PasswordConfig{
+		Algorithm:     os.Getenv("PASSWORD_HASH_ALGORITHM"),
+		BcryptCost:    12,
+		Argon2Memory:  64 * 1024,
+		Argon2Time:    3,
+		Argon2Threads: 2,
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "argon2id"
+	}
+	if v, err := strconv.Atoi(os.Getenv("PASSWORD_BCRYPT_COST")); err == nil {
+		cfg.BcryptCost = v
+	}
+	if v, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_MEMORY_KB"), 10, 32); err == nil {
+		cfg.Argon2Memory = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_TIME"), 10, 32); err == nil {
+		cfg.Argon2Time = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_THREADS"), 10, 8); err == nil {
+		cfg.Argon2Threads = uint8(v)
+	}
+	return cfg
+}
+
+// NewPasswordManagerFromConfig uses the configured algorithm for new hashes
+// while still verifying hashes produced by the other one.
+func NewPasswordManagerFromConfig(cfg *PasswordConfig) *PasswordManager {
+	argon := NewArgon2idHasher(cfg.Argon2Memory, cfg.Argon2Time, cfg.Argon2Threads)
+	bc := NewBcryptHasher(cfg.BcryptCost)
+	if cfg.Algorithm == bc.Algorithm() {
+		return NewPasswordManager(bc, argon)
+	}
+	return NewPasswordManager(argon, bc)
+}
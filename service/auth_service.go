@@ -6,33 +6,58 @@ import (
 	"errors"
 	"golang-rest-user/enums"
 	"golang-rest-user/provider/redisProvider"
-	"golang-rest-user/utils"
+	"log"
 	"time"
 
 	"golang-rest-user/dto"
 	"golang-rest-user/models"
 	"golang-rest-user/repository"
 	"golang-rest-user/security"
+	"golang-rest-user/utils"
 
 	"github.com/google/uuid"
 )
 
 type AuthService interface {
 	Register(req dto.CreateUserRequest) (*dto.UserResponse, error)
-	Login(tenantCode string, req dto.LoginRequest) (map[string]interface{}, error)
-	Refresh(tenantCode, refreshToken string) (map[string]interface{}, error)
-	Logout(tenantCode, refreshToken string) error
+	Login(tenantCode string, req dto.LoginRequest, client dto.ClientInfo) (map[string]interface{}, error)
+	Refresh(tenantCode, refreshToken string, client dto.ClientInfo) (map[string]interface{}, error)
+	Logout(tenantCode, refreshToken string, all bool) error
+	// VerifyMFA completes a login that returned an mfa_token. For a user
+	// who must enroll first, the code confirms the enrollment.
+	VerifyMFA(tenantCode string, req dto.MFAVerifyRequest, client dto.ClientInfo) (map[string]interface{}, error)
+	// EnrollMFA starts the enrollment required before a login can finish.
+	EnrollMFA(tenantCode, mfaToken string) (*dto.MFAEnrollResponse, error)
 }
 
+// refreshTTL is the lifetime of a refresh token and so of an idle session.
+const refreshTTL = 604800 * time.Second
+
+const (
+	mfaChallengeTTL = 5 * time.Minute
+	// wrong codes per challenge before the password is asked again
+	mfaChallengeMaxAttempts = 5
+)
+
+var ErrMFAChallengeInvalid = errors.New("mfa token is invalid or expired")
+
 type authService struct {
 	userRepo   repository.UserRepo
 	jwtManager *security.Manager
+	passwords  *security.PasswordManager
+	settings   SettingsFunc
+	verifier   EmailVerificationService
+	mfa        MFAService
 }
 
-func NewAuthService(userRepo repository.UserRepo, jwtManager *security.Manager) AuthService {
+func NewAuthService(userRepo repository.UserRepo, jwtManager *security.Manager, passwords *security.PasswordManager, settings SettingsFunc, verifier EmailVerificationService, mfa MFAService) AuthService {
 	return &authService{
 		userRepo:   userRepo,
 		jwtManager: jwtManager,
+		passwords:  passwords,
+		settings:   settings,
+		verifier:   verifier,
+		mfa:        mfa,
 	}
 }
 
@@ -41,45 +66,183 @@ func (s *authService) Register(req dto.CreateUserRequest) (*dto.UserResponse, er
 		return nil, errors.New("username already exists")
 	}
 
-	encryptedPass, err := utils.AESGCMEncrypt(req.Password)
+	hashedPass, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &models.User{
 		Username: req.Username,
-		Password: encryptedPass,
+		Password: hashedPass,
 		FullName: req.FullName,
+		Role:     enums.TenantRoleMember,
 	}
 	user.UUID = uuid.New().String()
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
+	// the account exists either way, the user can ask for another mail
+	if err := s.verifier.Send(user); err != nil {
+		log.Printf("send verification mail to user %d: %v", user.ID, err)
+	}
 
 	return convertToUserResponse(user), nil
 }
 
-func (s *authService) Login(tenantCode string, req dto.LoginRequest) (map[string]interface{}, error) {
+func (s *authService) Login(tenantCode string, req dto.LoginRequest, client dto.ClientInfo) (map[string]interface{}, error) {
+	loginSettings := s.settings().Login.WithDefaults()
+	if err := checkLoginAllowed(tenantCode, req.Username, client.IP, loginSettings); err != nil {
+		return nil, err
+	}
 
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
+		// unknown usernames count too, so they look the same as wrong passwords
+		recordLoginFailure(tenantCode, req.Username, client.IP, loginSettings)
 		return nil, errors.New("invalid credentials")
 	}
 
-	decryptedPass, _ := utils.AESGCMDecrypt(user.Password)
-	if decryptedPass != req.Password {
+	ok, needsRehash, err := s.passwords.Verify(user.Password, req.Password)
+	if err != nil || !ok {
+		recordLoginFailure(tenantCode, req.Username, client.IP, loginSettings)
 		return nil, errors.New("invalid credentials")
 	}
+	if needsRehash {
+		s.rehashPassword(user.ID, req.Password)
+	}
+	if !user.EmailVerified && !loginSettings.AllowUnverifiedEmail {
+		return nil, ErrEmailNotVerified
+	}
+	if user.MFAEnabled || loginSettings.RequireMFA {
+		// failures are only cleared once the second factor is right too
+		return s.mfaChallenge(tenantCode, user, req.DeviceName, !user.MFAEnabled)
+	}
+	s.clearLoginFailures(tenantCode, user)
+
+	familyID, err := redisProvider.CreateFamily(tenantCode, user.ID, refreshTTL, sessionMeta(req.DeviceName, client))
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(tenantCode, user, familyID, "")
+}
+
+func (s *authService) clearLoginFailures(tenantCode string, user *models.User) {
+	if err := redisProvider.ClearLoginFailures(tenantCode, user.Username); err != nil {
+		log.Printf("clear login failures for user %d: %v", user.ID, err)
+	}
+}
+
+// mfaChallenge answers a correct password with a short-lived token to be
+// exchanged, together with the second factor, for the session tokens.
+func (s *authService) mfaChallenge(tenantCode string, user *models.User, device string, enroll bool) (map[string]interface{}, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	challenge := redisProvider.MFAChallenge{UserID: user.ID, Device: device, Enroll: enroll}
+	if err := redisProvider.CreateMFAChallenge(tenantCode, hashToken(token), challenge, mfaChallengeTTL); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"mfa_required":            true,
+		"mfa_enrollment_required": enroll,
+		"mfa_token":               token,
+		"mfa_expires_in":          int(mfaChallengeTTL / time.Second),
+	}, nil
+}
+
+func (s *authService) VerifyMFA(tenantCode string, req dto.MFAVerifyRequest, client dto.ClientInfo) (map[string]interface{}, error) {
+	hash := hashToken(req.MFAToken)
+	challenge, err := redisProvider.GetMFAChallenge(tenantCode, hash)
+	if errors.Is(err, redisProvider.ErrTokenInvalid) {
+		return nil, ErrMFAChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		return nil, ErrMFAChallengeInvalid
+	}
+	loginSettings := s.settings().Login.WithDefaults()
+	if err := checkLoginAllowed(tenantCode, user.Username, client.IP, loginSettings); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes *dto.MFARecoveryCodesResponse
+	if challenge.Enroll {
+		// Confirm counts a wrong code as a failed login itself
+		recoveryCodes, err = s.mfa.Confirm(user.ID, client, req.Code)
+	} else if err = s.mfa.Check(user, req.Code); errors.Is(err, ErrMFAInvalidCode) {
+		// wrong codes count like wrong passwords, on top of the per
+		// challenge limit
+		recordLoginFailure(tenantCode, user.Username, client.IP, loginSettings)
+	}
+	if errors.Is(err, ErrMFAInvalidCode) {
+		if err := redisProvider.FailMFAChallenge(tenantCode, hash, mfaChallengeMaxAttempts); err != nil {
+			log.Printf("count mfa failure for user %d: %v", user.ID, err)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existed, err := redisProvider.DeleteMFAChallenge(tenantCode, hash); err != nil {
+		return nil, err
+	} else if !existed {
+		return nil, ErrMFAChallengeInvalid
+	}
+	s.clearLoginFailures(tenantCode, user)
+
+	familyID, err := redisProvider.CreateFamily(tenantCode, user.ID, refreshTTL, sessionMeta(challenge.Device, client))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.issueTokens(tenantCode, user, familyID, "")
+	if err != nil {
+		return nil, err
+	}
+	if recoveryCodes != nil {
+		tokens["recovery_codes"] = recoveryCodes.RecoveryCodes
+	}
+	return tokens, nil
+}
+
+func (s *authService) EnrollMFA(tenantCode, mfaToken string) (*dto.MFAEnrollResponse, error) {
+	challenge, err := redisProvider.GetMFAChallenge(tenantCode, hashToken(mfaToken))
+	if errors.Is(err, redisProvider.ErrTokenInvalid) {
+		return nil, ErrMFAChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Enroll {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return s.mfa.Enroll(challenge.UserID)
+}
+
+func sessionMeta(device string, client dto.ClientInfo) redisProvider.SessionMeta {
+	userAgent := client.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	return redisProvider.SessionMeta{Device: device, UserAgent: userAgent, IP: client.IP}
+}
 
-	ver := redisProvider.GetTokenVer(user.ID, tenantCode)
+// issueTokens creates an access token and the next refresh token of the
+// family. parent is the hash of the refresh token being rotated.
+func (s *authService) issueTokens(tenantCode string, user *models.User, familyID, parent string) (map[string]interface{}, error) {
+	userID := user.ID
+	ver := redisProvider.GetTokenVer(userID, tenantCode)
 
-	aToken, err := s.jwtManager.GenerateToken(user.ID, user.Username, tenantCode, enums.TokenTypeAccess, 900, ver)
+	aToken, err := s.jwtManager.GenerateToken(userID, user.Username, tenantCode, familyID, user.Role, enums.TokenTypeAccess, 900, ver)
 	if err != nil {
 		return nil, err
 	}
 
-	rToken, err := s.jwtManager.GenerateToken(user.ID, user.Username, tenantCode, enums.TokenTypeRefresh, 604800, ver)
+	rToken, err := s.jwtManager.GenerateToken(userID, user.Username, tenantCode, familyID, user.Role, enums.TokenTypeRefresh, int(refreshTTL/time.Second), ver)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +250,7 @@ func (s *authService) Login(tenantCode string, req dto.LoginRequest) (map[string
 	hash := hashToken(rToken.Token)
 	ttl := time.Duration(rToken.ExpiresIn) * time.Second
 
-	err = redisProvider.Create(hash, user.ID, tenantCode, ttl)
+	err = redisProvider.Create(hash, userID, tenantCode, familyID, parent, ttl)
 	if err != nil {
 		return nil, err
 	}
@@ -100,12 +263,25 @@ func (s *authService) Login(tenantCode string, req dto.LoginRequest) (map[string
 	}, nil
 }
 
+// rehashPassword upgrades a legacy or outdated hash after a successful login.
+// Failure is logged only, the user can still log in with the old hash.
+func (s *authService) rehashPassword(userID uint, password string) {
+	hashed, err := s.passwords.Hash(password)
+	if err != nil {
+		log.Printf("rehash password for user %d: %v", userID, err)
+		return
+	}
+	if err := s.userRepo.UpdatePassword(userID, hashed); err != nil {
+		log.Printf("rehash password for user %d: %v", userID, err)
+	}
+}
+
 func hashToken(rToken string) string {
 	h := sha256.Sum256([]byte(rToken))
 	return hex.EncodeToString(h[:])
 }
 
-func (s *authService) Refresh(tenantCode, rToken string) (map[string]interface{}, error) {
+func (s *authService) Refresh(tenantCode, rToken string, client dto.ClientInfo) (map[string]interface{}, error) {
 	claims, err := s.jwtManager.ParseToken(rToken)
 
 	if claims == nil {
@@ -119,37 +295,48 @@ func (s *authService) Refresh(tenantCode, rToken string) (map[string]interface{}
 	if tenantCode != claims.TenantCode {
 		return nil, errors.New("invalid tenant code")
 	}
-
-	if err := redisProvider.FindValidByHash(hashToken(rToken), claims.TenantCode, claims.UserID); err != nil {
-		return nil, errors.New("refresh token revoked")
+	// reloaded so a role change reaches the new access token
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
 	}
 
-	ver := redisProvider.GetTokenVer(claims.UserID, claims.TenantCode)
-
-	//revoke old refresh token
-	if err = redisProvider.Revoke(hashToken(rToken), claims.TenantCode, claims.UserID); err != nil {
-		return nil, err
+	hash := hashToken(rToken)
+	familyID, err := redisProvider.Rotate(hash, claims.TenantCode, claims.UserID)
+	if errors.Is(err, redisProvider.ErrRefreshReused) {
+		// a rotated token came back: either the client or an attacker holds
+		// a stolen copy, so nobody in this family keeps access
+		if err := redisProvider.RevokeFamily(claims.TenantCode, claims.UserID, familyID); err != nil {
+			log.Printf("revoke family %s: %v", familyID, err)
+		}
+		emitSecurityEvent(SecurityEvent{
+			Type:       enums.SecurityEventRefreshTokenReuse,
+			TenantCode: claims.TenantCode,
+			UserID:     claims.UserID,
+			Detail:     map[string]string{"family": familyID, "token_id": claims.ID},
+		})
+		return nil, errors.New("refresh token reuse detected")
 	}
-
-	newAToken, _ := s.jwtManager.GenerateToken(claims.UserID, claims.Username, claims.TenantCode, enums.TokenTypeAccess, 900, ver)
-	newRToken, _ := s.jwtManager.GenerateToken(claims.UserID, claims.Username, claims.TenantCode, enums.TokenTypeRefresh, 604800, ver)
-
-	hash := hashToken(newRToken.Token)
-	ttl := time.Duration(newRToken.ExpiresIn) * time.Second
-
-	if err = redisProvider.Create(hash, claims.UserID, claims.TenantCode, ttl); err != nil {
-		return nil, err
+	if err != nil {
+		return nil, errors.New("refresh token revoked")
+	}
+	if familyID == "" {
+		if familyID, err = redisProvider.CreateFamily(claims.TenantCode, claims.UserID, refreshTTL, sessionMeta("", client)); err != nil {
+			return nil, err
+		}
+	} else if err := redisProvider.TouchFamily(claims.TenantCode, claims.UserID, familyID, sessionMeta("", client)); errors.Is(err, redisProvider.ErrRefreshRevoked) {
+		// logged out or revoked for reuse since the rotation
+		return nil, errors.New("refresh token revoked")
+	} else if err != nil {
+		log.Printf("touch session %s: %v", familyID, err)
 	}
 
-	return map[string]interface{}{
-		"access_token":       newAToken.Token,
-		"access_expires_in":  newAToken.ExpiresIn,
-		"refresh_token":      newRToken.Token,
-		"refresh_expires_in": newRToken.ExpiresIn,
-	}, nil
+	return s.issueTokens(claims.TenantCode, user, familyID, hash)
 }
 
-func (s *authService) Logout(tenantCode, rToken string) error {
+// Logout ends the session the refresh token belongs to, or with all every
+// session of the user, including outstanding access tokens.
+func (s *authService) Logout(tenantCode, rToken string, all bool) error {
 	claims, err := s.jwtManager.ParseToken(rToken)
 	if claims == nil {
 		return errors.New("invalid token")
@@ -161,9 +348,22 @@ func (s *authService) Logout(tenantCode, rToken string) error {
 		return errors.New("invalid tenant code")
 	}
 
-	if err := redisProvider.IncreaseTokenVer(claims.UserID, claims.TenantCode); err != nil {
-		return err
+	if all {
+		if err := redisProvider.IncreaseTokenVer(claims.UserID, claims.TenantCode); err != nil {
+			return err
+		}
+		return redisProvider.RevokeAllByUser(claims.TenantCode, claims.UserID)
 	}
 
-	return redisProvider.RevokeAllByUser(claims.TenantCode, claims.UserID)
+	hash := hashToken(rToken)
+	familyID := claims.SessionID
+	if familyID == "" {
+		if familyID, err = redisProvider.FamilyOf(hash, claims.TenantCode, claims.UserID); err != nil {
+			return err
+		}
+	}
+	if familyID == "" {
+		return redisProvider.Revoke(hash, claims.TenantCode, claims.UserID)
+	}
+	return redisProvider.RevokeFamily(claims.TenantCode, claims.UserID, familyID)
 }
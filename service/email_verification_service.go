@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"golang-rest-user/mailer"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/repository"
+	"golang-rest-user/utils"
+	"time"
+)
+
+const (
+	emailVerifyTTL = 24 * time.Hour
+	// at most emailVerifyMaxRequests resends per username and window
+	emailVerifyWindow      = time.Hour
+	emailVerifyMaxRequests = 3
+)
+
+var (
+	ErrEmailVerifyInvalid = errors.New("verification token is invalid or expired")
+	ErrEmailNotVerified   = errors.New("email is not verified")
+)
+
+// EmailVerificationService proves that a registered user owns the email
+// used as username, through a single-use token sent by mail.
+type EmailVerificationService interface {
+	Send(user *models.User) error
+	Verify(token string) error
+	Resend(username string) error
+}
+
+type emailVerificationService struct {
+	tenantCode string
+	userRepo   repository.UserRepo
+	mailer     mailer.Mailer
+	publicURL  string
+}
+
+func NewEmailVerificationService(tenantCode string, userRepo repository.UserRepo, m mailer.Mailer, publicURL string) EmailVerificationService {
+	return &emailVerificationService{
+		tenantCode: tenantCode,
+		userRepo:   userRepo,
+		mailer:     m,
+		publicURL:  publicURL,
+	}
+}
+
+func (s *emailVerificationService) Send(user *models.User) error {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	if err := redisProvider.CreateUserToken(s.tenantCode, redisProvider.TokenPurposeEmailVerify, user.ID, hashToken(token), emailVerifyTTL); err != nil {
+		return err
+	}
+
+	sendMail(s.mailer, mailer.Message{
+		To:      user.Username,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the following to verify your email within %d hours:\n\n%s\n",
+			user.FullName,
+			int(emailVerifyTTL/time.Hour),
+			link(s.publicURL, "/verify-email", token),
+		),
+	})
+	return nil
+}
+
+func (s *emailVerificationService) Verify(token string) error {
+	userID, err := redisProvider.ConsumeUserToken(s.tenantCode, redisProvider.TokenPurposeEmailVerify, hashToken(token))
+	if errors.Is(err, redisProvider.ErrTokenInvalid) {
+		return ErrEmailVerifyInvalid
+	}
+	if err != nil {
+		return err
+	}
+	return s.userRepo.MarkEmailVerified(userID)
+}
+
+// Resend mails a new token to an unverified user. Like Forgot it answers the
+// same whether or not the user exists.
+func (s *emailVerificationService) Resend(username string) error {
+	requests, err := redisProvider.RecordUserTokenRequest(s.tenantCode, redisProvider.TokenPurposeEmailVerify, username, emailVerifyWindow)
+	if err != nil {
+		return err
+	}
+	if requests > emailVerifyMaxRequests {
+		return ErrTooManyRequests
+	}
+
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil || user.EmailVerified {
+		return nil
+	}
+	return s.Send(user)
+}
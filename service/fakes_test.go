@@ -0,0 +1,434 @@
+package service
+
+import (
+	"fmt"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// zoneStore is an in-memory tenant database behind the fake repositories of
+// the zone tests. Repository methods a test does not use are left nil and
+// panic when called.
+type zoneStore struct {
+	zones  map[uint]*models.Zone
+	grants []*models.UserZone
+	users  map[uint]*models.User
+	roles  map[string]*models.ZoneRole
+	trash  map[uint]*models.TrashedZone
+	types  []models.ZoneType
+	// trashed numbers the trash entries
+	trashed uint
+}
+
+func newZoneStore() *zoneStore {
+	return &zoneStore{
+		zones: map[uint]*models.Zone{},
+		users: map[uint]*models.User{},
+		roles: map[string]*models.ZoneRole{},
+		trash: map[uint]*models.TrashedZone{},
+	}
+}
+
+// addZone creates zone id under parent, nil for a root zone.
+func (s *zoneStore) addZone(id uint, parent *models.Zone) *models.Zone {
+	zone := &models.Zone{Name: fmt.Sprintf("zone %d", id), Type: "area", Level: 1}
+	zone.ID = id
+	zone.UUID = fmt.Sprintf("zone-%d", id)
+	zone.Path = fmt.Sprintf("%d/", id)
+	if parent != nil {
+		zone.ParentID = &parent.ID
+		zone.Level = parent.Level + 1
+		zone.Path = parent.Path + zone.Path
+	}
+	s.zones[id] = zone
+	return zone
+}
+
+func (s *zoneStore) addUser(id uint) *models.User {
+	user := &models.User{Username: fmt.Sprintf("user%d", id)}
+	user.ID = id
+	user.UUID = fmt.Sprintf("user-%d", id)
+	s.users[id] = user
+	return user
+}
+
+func (s *zoneStore) addRole(name string, actions ...enums.ZoneAction) {
+	s.roles[name] = &models.ZoneRole{Name: name, Actions: actions}
+}
+
+func (s *zoneStore) grant(userID uint, zone *models.Zone, role enums.UserPermission) {
+	s.grants = append(s.grants, &models.UserZone{UserID: userID, ZoneID: zone.ID, Permission: role})
+}
+
+func (s *zoneStore) grantOf(userID, zoneID uint) *models.UserZone {
+	for _, g := range s.grants {
+		if g.UserID == userID && g.ZoneID == zoneID && !g.DeletedAt.Valid {
+			return g
+		}
+	}
+	return nil
+}
+
+func (s *zoneStore) live(id uint) (*models.Zone, bool) {
+	zone, ok := s.zones[id]
+	if !ok || zone.DeletedAt.Valid {
+		return nil, false
+	}
+	copied := *zone
+	return &copied, true
+}
+
+// services wires the zone and share services to the store the way
+// TenantInfo.InitService does.
+func (s *zoneStore) services() (ZoneService, ShareService) {
+	zoneRepo := &fakeZoneRepo{s: s}
+	userZoneRepo := &fakeUserZoneRepo{s: s}
+	roles := NewZoneRoleService(&fakeZoneRoleRepo{s: s}, userZoneRepo)
+	authorizer := NewZoneAuthorizer(userZoneRepo, roles)
+	types := NewZoneTypeService(&fakeZoneTypeRepo{s: s}, zoneRepo)
+	zones := NewZoneService(zoneRepo, userZoneRepo, &fakeZoneTrashRepo{s: s}, authorizer, types)
+	shares := NewShareService(userZoneRepo, zoneRepo, &fakeUserRepo{s: s}, authorizer)
+	return zones, shares
+}
+
+type fakeZoneRepo struct {
+	repository.ZoneRepo
+	s *zoneStore
+}
+
+func (r *fakeZoneRepo) GetByID(id uint) (*models.Zone, error) {
+	if zone, ok := r.s.live(id); ok {
+		return zone, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeZoneRepo) GetByUUID(uuid string) (*models.Zone, error) {
+	for id, zone := range r.s.zones {
+		if zone.UUID == uuid {
+			return r.GetByID(id)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeZoneRepo) Update(zone *models.Zone) error {
+	copied := *zone
+	r.s.zones[zone.ID] = &copied
+	return nil
+}
+
+func (r *fakeZoneRepo) GetSubtreeByPath(path string) ([]models.Zone, error) {
+	return r.GetSubtreeToLevel(path, math.MaxInt, true)
+}
+
+func (r *fakeZoneRepo) GetSubtreeToLevel(path string, maxLevel int, withMetadata bool) ([]models.Zone, error) {
+	var zones []models.Zone
+	for id, zone := range r.s.zones {
+		if live, ok := r.s.live(id); ok && strings.HasPrefix(zone.Path, path) && zone.Level <= maxLevel {
+			zones = append(zones, *live)
+		}
+	}
+	byLevel(zones)
+	return zones, nil
+}
+
+// CountByType counts trashed zones too, like zoneRepoImpl.
+func (r *fakeZoneRepo) CountByType(zoneType string) (int64, error) {
+	var count int64
+	for _, zone := range r.s.zones {
+		if zone.Type == zoneType {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func byLevel(zones []models.Zone) {
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].Level != zones[j].Level {
+			return zones[i].Level < zones[j].Level
+		}
+		return zones[i].ID < zones[j].ID
+	})
+}
+
+func (r *fakeZoneRepo) CountChildren(ids []uint) (map[uint]int64, error) {
+	counts := map[uint]int64{}
+	for id := range r.s.zones {
+		if zone, ok := r.s.live(id); ok && zone.ParentID != nil && slices.Contains(ids, *zone.ParentID) {
+			counts[*zone.ParentID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakeZoneRepo) MoveSubtree(zoneID, parentID uint) (int64, error) {
+	zone, parent := r.s.zones[zoneID], r.s.zones[parentID]
+	if strings.HasPrefix(parent.Path, zone.Path) {
+		return 0, repository.ErrZoneCycle
+	}
+	oldPath, newPath := zone.Path, fmt.Sprintf("%s%d/", parent.Path, zone.ID)
+	delta := parent.Level + 1 - zone.Level
+	var moved int64
+	for _, z := range r.s.zones {
+		if strings.HasPrefix(z.Path, oldPath) {
+			z.Path = newPath + strings.TrimPrefix(z.Path, oldPath)
+			z.Level += delta
+			if !z.DeletedAt.Valid {
+				moved++
+			}
+		}
+	}
+	zone.ParentID = &parent.ID
+	return moved, nil
+}
+
+type fakeUserZoneRepo struct {
+	repository.UserZoneRepo
+	s *zoneStore
+}
+
+func (r *fakeUserZoneRepo) Create(userZone *models.UserZone) error {
+	if r.s.grantOf(userZone.UserID, userZone.ZoneID) != nil {
+		return gorm.ErrDuplicatedKey
+	}
+	r.s.grants = append(r.s.grants, userZone)
+	return nil
+}
+
+func (r *fakeUserZoneRepo) Get(userID, zoneID uint) (*models.UserZone, error) {
+	if g := r.s.grantOf(userID, zoneID); g != nil {
+		return g, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserZoneRepo) UpdatePermission(userID, zoneID uint, permission enums.UserPermission) error {
+	if g := r.s.grantOf(userID, zoneID); g != nil {
+		g.Permission = permission
+	}
+	return nil
+}
+
+// GetPermission is the role of the deepest live grant above or on path.
+func (r *fakeUserZoneRepo) GetPermission(userID uint, path string) (string, error) {
+	var permission string
+	level := 0
+	for _, g := range r.s.grants {
+		zone, ok := r.s.live(g.ZoneID)
+		if g.UserID != userID || g.DeletedAt.Valid || !ok || !strings.HasPrefix(path, zone.Path) {
+			continue
+		}
+		if zone.Level > level {
+			permission, level = string(g.Permission), zone.Level
+		}
+	}
+	return permission, nil
+}
+
+func (r *fakeUserZoneRepo) ListGrants(userID uint) ([]repository.ZoneGrant, error) {
+	var grants []repository.ZoneGrant
+	for _, g := range r.s.grants {
+		zone, ok := r.s.live(g.ZoneID)
+		if g.UserID != userID || g.DeletedAt.Valid || !ok {
+			continue
+		}
+		grants = append(grants, repository.ZoneGrant{
+			UUID:       zone.UUID,
+			Name:       zone.Name,
+			Type:       zone.Type,
+			Path:       zone.Path,
+			Permission: g.Permission,
+		})
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Path < grants[j].Path })
+	return grants, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepo
+	s *zoneStore
+}
+
+func (r *fakeUserRepo) GetByUUID(uuid string) (*models.User, error) {
+	for _, user := range r.s.users {
+		if user.UUID == uuid {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeZoneRoleRepo struct {
+	repository.ZoneRoleRepo
+	s *zoneStore
+}
+
+func (r *fakeZoneRoleRepo) GetByName(name string) (*models.ZoneRole, error) {
+	if role, ok := r.s.roles[name]; ok {
+		return role, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeZoneTypeRepo serves the store's types, zones are free-form until a
+// test registers one.
+type fakeZoneTypeRepo struct {
+	repository.ZoneTypeRepo
+	s *zoneStore
+}
+
+func (r *fakeZoneTypeRepo) List() ([]models.ZoneType, error) {
+	return r.s.types, nil
+}
+
+func (r *fakeZoneTypeRepo) Delete(name string) (int64, error) {
+	n := len(r.s.types)
+	r.s.types = slices.DeleteFunc(r.s.types, func(t models.ZoneType) bool { return t.Name == name })
+	return int64(n - len(r.s.types)), nil
+}
+
+// fakeZoneTrashRepo soft-deletes zones and grants the way zoneTrashRepo
+// does, every row of a trash entry carries its ID. Entries are numbered in
+// the order they were trashed.
+type fakeZoneTrashRepo struct {
+	repository.ZoneTrashRepo
+	s *zoneStore
+}
+
+func (r *fakeZoneTrashRepo) Trash(zone *models.Zone, deletedBy uint) (int64, error) {
+	r.s.trashed++
+	entry := &models.TrashedZone{ID: r.s.trashed, ZoneID: zone.ID, DeletedBy: &deletedBy, TrashedAt: time.Now()}
+	deleted := gorm.DeletedAt{Time: entry.TrashedAt, Valid: true}
+	var trashed int64
+	for id, z := range r.s.zones {
+		if _, ok := r.s.live(id); ok && strings.HasPrefix(z.Path, zone.Path) {
+			z.DeletedAt, z.TrashID = deleted, &entry.ID
+			trashed++
+			for _, g := range r.s.grants {
+				if g.ZoneID == id && !g.DeletedAt.Valid {
+					g.DeletedAt, g.TrashID = deleted, &entry.ID
+				}
+			}
+		}
+	}
+	r.s.trash[entry.ID] = entry
+	return trashed, nil
+}
+
+func (r *fakeZoneTrashRepo) item(t *models.TrashedZone) *repository.TrashItem {
+	zone := r.s.zones[t.ZoneID]
+	item := &repository.TrashItem{
+		TrashID:    t.ID,
+		ZoneID:     zone.ID,
+		UUID:       zone.UUID,
+		Name:       zone.Name,
+		Type:       zone.Type,
+		Path:       zone.Path,
+		ParentID:   zone.ParentID,
+		DeletedBy:  t.DeletedBy,
+		TrashedAt:  t.TrashedAt,
+		Restorable: true,
+	}
+	if zone.ParentID != nil {
+		_, item.Restorable = r.s.live(*zone.ParentID)
+	}
+	return item
+}
+
+func (r *fakeZoneTrashRepo) Get(uuid string) (*repository.TrashItem, error) {
+	for _, t := range r.s.trash {
+		if r.s.zones[t.ZoneID].UUID == uuid {
+			return r.item(t), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeZoneTrashRepo) IsOwner(item *repository.TrashItem, userID uint) (bool, error) {
+	for _, g := range r.s.grants {
+		if g.ZoneID == item.ZoneID && g.UserID == userID && g.Permission == enums.UserOwner &&
+			g.TrashID != nil && *g.TrashID == item.TrashID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeZoneTrashRepo) Restore(item *repository.TrashItem, check func(zones []models.Zone, parent *models.Zone) error) (int64, error) {
+	if _, ok := r.s.trash[item.TrashID]; !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	var parent *models.Zone
+	if item.ParentID != nil {
+		var ok bool
+		if parent, ok = r.s.live(*item.ParentID); !ok {
+			return 0, repository.ErrZoneParentMissing
+		}
+	}
+	var zones []models.Zone
+	for _, z := range r.s.zones {
+		if z.TrashID != nil && *z.TrashID == item.TrashID {
+			zones = append(zones, *z)
+		}
+	}
+	byLevel(zones)
+	if err := check(zones, parent); err != nil {
+		return 0, err
+	}
+	var restored int64
+	for _, z := range r.s.zones {
+		if z.TrashID != nil && *z.TrashID == item.TrashID {
+			z.DeletedAt, z.TrashID = gorm.DeletedAt{}, nil
+			restored++
+		}
+	}
+	for _, g := range r.s.grants {
+		if g.TrashID != nil && *g.TrashID == item.TrashID {
+			g.DeletedAt, g.TrashID = gorm.DeletedAt{}, nil
+		}
+	}
+	delete(r.s.trash, item.TrashID)
+	return restored, nil
+}
+
+func (r *fakeZoneTrashRepo) Purge(item *repository.TrashItem) (int64, error) {
+	if _, ok := r.s.trash[item.TrashID]; !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	var purged int64
+	for id, z := range r.s.zones {
+		if !strings.HasPrefix(z.Path, item.Path) || !z.DeletedAt.Valid {
+			continue
+		}
+		delete(r.s.zones, id)
+		purged++
+		r.s.grants = slices.DeleteFunc(r.s.grants, func(g *models.UserZone) bool { return g.ZoneID == id })
+		for trashID, t := range r.s.trash {
+			if t.ZoneID == id {
+				delete(r.s.trash, trashID)
+			}
+		}
+	}
+	return purged, nil
+}
+
+func (r *fakeZoneTrashRepo) ListExpired(before time.Time) ([]repository.TrashItem, error) {
+	var items []repository.TrashItem
+	for _, t := range r.s.trash {
+		if t.TrashedAt.Before(before) {
+			items = append(items, *r.item(t))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].TrashedAt.Before(items[j].TrashedAt) })
+	return items, nil
+}
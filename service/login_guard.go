@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/redisProvider"
+	"log"
+	"time"
+)
+
+// ErrLoginUnavailable is returned when the failure counters cannot be read.
+// Logins fail closed: without them a lockout could not be enforced.
+var ErrLoginUnavailable = errors.New("login is temporarily unavailable, try again later")
+
+// SettingsFunc returns the current settings of the tenant a service runs
+// for; they can change at runtime.
+type SettingsFunc func() models.TenantSettings
+
+// LoginThrottledError rejects a login before the password is checked.
+type LoginThrottledError struct {
+	Locked     bool
+	RetryAfter time.Duration
+	reason     string
+}
+
+func (e *LoginThrottledError) Error() string {
+	return e.reason
+}
+
+// checkLoginAllowed returns a *LoginThrottledError while the account is
+// locked, the username must still wait, or the IP has too many failures, and
+// ErrLoginUnavailable if Redis cannot tell.
+func checkLoginAllowed(tenantCode, username, ip string, settings models.LoginSettings) error {
+	err := loginAllowed(tenantCode, username, ip, settings)
+	var throttled *LoginThrottledError
+	if err != nil && !errors.As(err, &throttled) {
+		log.Printf("check login failures: %v", err)
+		return ErrLoginUnavailable
+	}
+	return err
+}
+
+func loginAllowed(tenantCode, username, ip string, settings models.LoginSettings) error {
+	if d, err := redisProvider.AccountLock(tenantCode, username); err != nil {
+		return err
+	} else if d > 0 {
+		return &LoginThrottledError{Locked: true, RetryAfter: d, reason: "account is temporarily locked"}
+	}
+	if d, err := redisProvider.LoginDelay(tenantCode, username); err != nil {
+		return err
+	} else if d > 0 {
+		return &LoginThrottledError{RetryAfter: d, reason: "too many failed attempts, try again later"}
+	}
+	window := time.Duration(settings.FailureWindowSeconds) * time.Second
+	_, ipFailures, err := redisProvider.CountLoginFailures(tenantCode, username, ip, window)
+	if err != nil {
+		return err
+	}
+	if ipFailures >= int64(settings.MaxFailuresPerIP) {
+		return &LoginThrottledError{RetryAfter: window, reason: "too many failed attempts from this address"}
+	}
+	return nil
+}
+
+// recordLoginFailure counts the failure and locks or delays the username once
+// the tenant's thresholds are reached.
+func recordLoginFailure(tenantCode, username, ip string, settings models.LoginSettings) {
+	window := time.Duration(settings.FailureWindowSeconds) * time.Second
+	failures, _, err := redisProvider.RecordLoginFailure(tenantCode, username, ip, window)
+	if err != nil {
+		log.Printf("record login failure: %v", err)
+		return
+	}
+
+	if failures >= int64(settings.MaxFailures) {
+		lockout := time.Duration(settings.LockoutSeconds) * time.Second
+		if err := redisProvider.LockAccount(tenantCode, username, lockout); err != nil {
+			log.Printf("lock account: %v", err)
+			return
+		}
+		emitSecurityEvent(SecurityEvent{
+			Type:       enums.SecurityEventAccountLocked,
+			TenantCode: tenantCode,
+			Detail: map[string]string{
+				"username": username,
+				"ip":       ip,
+				"until":    time.Now().Add(lockout).UTC().Format(time.RFC3339),
+			},
+		})
+		return
+	}
+
+	if failures >= int64(settings.DelayAfterFailures) {
+		delay := time.Duration(settings.DelayBaseSeconds) * time.Second
+		for i := int64(settings.DelayAfterFailures); i < failures; i++ {
+			delay *= 2
+			if delay >= time.Duration(settings.DelayMaxSeconds)*time.Second {
+				delay = time.Duration(settings.DelayMaxSeconds) * time.Second
+				break
+			}
+		}
+		if err := redisProvider.DelayLogin(tenantCode, username, delay); err != nil {
+			log.Printf("delay login: %v", err)
+		}
+	}
+}
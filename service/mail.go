@@ -0,0 +1,29 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/mailer"
+	"log"
+)
+
+// ErrTooManyRequests is returned once a username asked for too many mails.
+var ErrTooManyRequests = errors.New("too many requests, try again later")
+
+// sendMail delivers msg in the background, so the response time does not
+// reveal whether a mail was sent, and logs delivery failures.
+func sendMail(m mailer.Mailer, msg mailer.Message) {
+	go func() {
+		if err := m.Send(msg); err != nil {
+			log.Printf("send mail %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+// link appends the token to the page of the public app, or returns just the
+// token when no public URL is configured.
+func link(baseURL, page, token string) string {
+	if baseURL == "" {
+		return token
+	}
+	return baseURL + page + "?token=" + token
+}
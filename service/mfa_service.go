@@ -0,0 +1,279 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/repository"
+	"golang-rest-user/security"
+	"golang-rest-user/utils"
+	"strings"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("mfa is already enabled")
+	ErrMFANotEnabled     = errors.New("mfa is not enabled")
+	ErrMFANotEnrolled    = errors.New("mfa enrollment has not been started")
+	ErrMFAInvalidCode    = errors.New("invalid mfa code")
+	ErrMFARequired       = errors.New("mfa is required by the tenant")
+)
+
+// MFAService manages TOTP second factors and their recovery codes.
+type MFAService interface {
+	Status(userID uint) (*dto.MFAStatusResponse, error)
+	// Enroll starts (or restarts) enrollment with a new secret. MFA is only
+	// enabled once Confirm sees a code generated from it.
+	Enroll(userID uint) (*dto.MFAEnrollResponse, error)
+	// Confirm, Disable and RegenerateRecoveryCodes count wrong codes as
+	// failed logins and refuse to check any while the user is throttled.
+	Confirm(userID uint, client dto.ClientInfo, code string) (*dto.MFARecoveryCodesResponse, error)
+	Disable(userID uint, client dto.ClientInfo, code string) error
+	RegenerateRecoveryCodes(userID uint, client dto.ClientInfo, code string) (*dto.MFARecoveryCodesResponse, error)
+	// Reset removes the MFA of a user who lost both the authenticator and
+	// the recovery codes.
+	Reset(uuid string) error
+	// Check accepts a TOTP code or an unused recovery code.
+	Check(user *models.User, code string) error
+}
+
+type mfaService struct {
+	tenantCode   string
+	issuer       string
+	userRepo     repository.UserRepo
+	recoveryRepo repository.RecoveryCodeRepo
+	settings     SettingsFunc
+}
+
+func NewMFAService(tenantCode, issuer string, userRepo repository.UserRepo, recoveryRepo repository.RecoveryCodeRepo, settings SettingsFunc) MFAService {
+	return &mfaService{
+		tenantCode:   tenantCode,
+		issuer:       issuer,
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		settings:     settings,
+	}
+}
+
+func (s *mfaService) Status(userID uint) (*dto.MFAStatusResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &dto.MFAStatusResponse{
+		Enabled:  user.MFAEnabled,
+		Required: s.settings().Login.RequireMFA,
+	}
+	if user.MFAEnabledAt != nil {
+		status.EnabledAt = user.MFAEnabledAt.Format(time.RFC3339)
+	}
+	if user.MFAEnabled {
+		if status.RecoveryCodesRemaining, err = s.recoveryRepo.CountUnused(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (s *mfaService) Enroll(userID uint) (*dto.MFAEnrollResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.AESGCMEncrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateMFA(user.ID, encrypted, false); err != nil {
+		return nil, err
+	}
+	return &dto.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: security.TOTPProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *mfaService) Confirm(userID uint, client dto.ClientInfo, code string) (*dto.MFARecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.guarded(user, client, func() error { return s.checkTOTP(user, code) }); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateMFA(user.ID, user.MFASecret, true); err != nil {
+		return nil, err
+	}
+	emitSecurityEvent(SecurityEvent{Type: enums.SecurityEventMFAEnabled, TenantCode: s.tenantCode, UserID: user.ID})
+	return codes, nil
+}
+
+func (s *mfaService) Disable(userID uint, client dto.ClientInfo, code string) error {
+	if s.settings().Login.RequireMFA {
+		return ErrMFARequired
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+	if err := s.guarded(user, client, func() error { return s.Check(user, code) }); err != nil {
+		return err
+	}
+	return s.remove(user.ID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userID uint, client dto.ClientInfo, code string) (*dto.MFARecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+	if err := s.guarded(user, client, func() error { return s.Check(user, code) }); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(user.ID)
+}
+
+func (s *mfaService) Reset(uuid string) error {
+	user, err := s.userRepo.GetByUUID(uuid)
+	if err != nil {
+		return err
+	}
+	return s.remove(user.ID)
+}
+
+func (s *mfaService) remove(userID uint) error {
+	if err := s.userRepo.UpdateMFA(userID, "", false); err != nil {
+		return err
+	}
+	if err := s.recoveryRepo.DeleteByUser(userID); err != nil {
+		return err
+	}
+	emitSecurityEvent(SecurityEvent{Type: enums.SecurityEventMFADisabled, TenantCode: s.tenantCode, UserID: userID})
+	return nil
+}
+
+func (s *mfaService) Check(user *models.User, code string) error {
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == security.TOTPDigits {
+		return s.checkTOTP(user, code)
+	}
+
+	used, err := s.recoveryRepo.Use(user.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrMFAInvalidCode
+	}
+	emitSecurityEvent(SecurityEvent{Type: enums.SecurityEventRecoveryCodeUsed, TenantCode: s.tenantCode, UserID: user.ID})
+	return nil
+}
+
+// guarded runs check behind the login guard, so that a stolen access token
+// cannot be used to brute-force the second factor.
+func (s *mfaService) guarded(user *models.User, client dto.ClientInfo, check func() error) error {
+	loginSettings := s.settings().Login.WithDefaults()
+	if err := checkLoginAllowed(s.tenantCode, user.Username, client.IP, loginSettings); err != nil {
+		return err
+	}
+	err := check()
+	if errors.Is(err, ErrMFAInvalidCode) {
+		recordLoginFailure(s.tenantCode, user.Username, client.IP, loginSettings)
+	}
+	return err
+}
+
+func (s *mfaService) checkTOTP(user *models.User, code string) error {
+	secret, err := utils.AESGCMDecrypt(user.MFASecret)
+	if err != nil {
+		return err
+	}
+	step, ok := security.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrMFAInvalidCode
+	}
+	// a code stays valid for the whole skew window, long enough to replay it
+	fresh, err := redisProvider.UseTOTPStep(s.tenantCode, user.ID, step, (2*security.TOTPSkew+1)*security.TOTPPeriod)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrMFAInvalidCode
+	}
+	return nil
+}
+
+// newRecoveryCodes replaces the user's recovery codes. The plain codes are
+// only ever returned here.
+func (s *mfaService) newRecoveryCodes(userID uint) (*dto.MFARecoveryCodesResponse, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+	if err := s.recoveryRepo.Replace(userID, hashes); err != nil {
+		return nil, err
+	}
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// 32 characters without i, l, o and 1, so every byte maps without bias
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+
+// randomRecoveryCode returns a code like "k7wq2-mz9xa", 50 random bits.
+func randomRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for i, v := range b {
+		if i == 5 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+	}
+	return sb.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/repository"
+	"golang-rest-user/security"
+	"golang-rest-user/utils"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+type fakeMFAUserRepo struct {
+	repository.UserRepo
+	user *models.User
+}
+
+func (r *fakeMFAUserRepo) GetByID(id uint) (*models.User, error) {
+	copied := *r.user
+	return &copied, nil
+}
+
+func (r *fakeMFAUserRepo) UpdateMFA(id uint, secret string, enabled bool) error {
+	r.user.MFASecret, r.user.MFAEnabled = secret, enabled
+	return nil
+}
+
+type fakeRecoveryCodeRepo struct {
+	repository.RecoveryCodeRepo
+}
+
+func (r *fakeRecoveryCodeRepo) Use(userID uint, hash string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeRecoveryCodeRepo) Replace(userID uint, hashes []string) error {
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) DeleteByUser(userID uint) error {
+	return nil
+}
+
+// newMFATest returns an MFA service for a user with MFA enabled, locked out
+// after maxFailures wrong codes, and the user's TOTP secret.
+func newMFATest(t *testing.T, maxFailures int) (MFAService, *fakeMFAUserRepo, string) {
+	t.Helper()
+	t.Setenv("APP_ENCRYPTION_KEY", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	t.Setenv("REDIS_ADDR", miniredis.RunT(t).Addr())
+	if err := redisProvider.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := utils.AESGCMEncrypt(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Username: "ann", MFASecret: encrypted, MFAEnabled: true}
+	user.ID = 1
+	users := &fakeMFAUserRepo{user: user}
+	settings := func() models.TenantSettings {
+		return models.TenantSettings{Login: models.LoginSettings{MaxFailures: maxFailures, DelayAfterFailures: 100}}
+	}
+	return NewMFAService("acme", "test", users, &fakeRecoveryCodeRepo{}, settings), users, secret
+}
+
+func TestMFACodeBruteForceIsLockedOut(t *testing.T) {
+	const maxFailures = 5
+	client := dto.ClientInfo{IP: "10.0.0.1"}
+	tests := []struct {
+		name  string
+		check func(s MFAService, code string) error
+	}{
+		{name: "disable", check: func(s MFAService, code string) error {
+			return s.Disable(1, client, code)
+		}},
+		{name: "recovery codes", check: func(s MFAService, code string) error {
+			_, err := s.RegenerateRecoveryCodes(1, client, code)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, secret := newMFATest(t, maxFailures)
+			for i := 0; i < maxFailures; i++ {
+				if err := tt.check(s, "000000"); !errors.Is(err, ErrMFAInvalidCode) {
+					t.Fatalf("attempt %d: err = %v, want %v", i+1, err, ErrMFAInvalidCode)
+				}
+			}
+
+			// even the right code is refused once the account is locked
+			code, err := security.TOTPCode(secret, security.TOTPStep(time.Now()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var throttled *LoginThrottledError
+			if err := tt.check(s, code); !errors.As(err, &throttled) || !throttled.Locked {
+				t.Fatalf("attempt %d: err = %v, want the account locked", maxFailures+1, err)
+			}
+		})
+	}
+}
+
+func TestMFAConfirmIsLockedOut(t *testing.T) {
+	const maxFailures = 3
+	s, users, _ := newMFATest(t, maxFailures)
+	users.user.MFAEnabled = false
+	client := dto.ClientInfo{IP: "10.0.0.1"}
+
+	for i := 0; i < maxFailures; i++ {
+		if _, err := s.Confirm(1, client, "000000"); !errors.Is(err, ErrMFAInvalidCode) {
+			t.Fatalf("attempt %d: err = %v, want %v", i+1, err, ErrMFAInvalidCode)
+		}
+	}
+	var throttled *LoginThrottledError
+	if _, err := s.Confirm(1, client, "000000"); !errors.As(err, &throttled) {
+		t.Fatalf("attempt %d: err = %v, want it throttled", maxFailures+1, err)
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/repository"
+	"golang-rest-user/security"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OperatorService interface {
+	Login(req dto.OperatorLoginRequest) (map[string]interface{}, error)
+	Refresh(refreshToken string) (map[string]interface{}, error)
+	Logout(refreshToken string) error
+	Create(req dto.CreateOperatorRequest) (*dto.OperatorResponse, error)
+	List(page, pageSize int, search string) ([]dto.OperatorResponse, int64, error)
+	Update(uuid string, req dto.UpdateOperatorRequest) (*dto.OperatorResponse, error)
+	Delete(uuid string, currentOperatorID uint) error
+	EnsureBootstrapAdmin(username, password string) error
+}
+
+type operatorService struct {
+	repo       repository.OperatorRepo
+	jwtManager *security.Manager
+	passwords  *security.PasswordManager
+}
+
+func NewOperatorService(repo repository.OperatorRepo, jwtManager *security.Manager, passwords *security.PasswordManager) OperatorService {
+	return &operatorService{
+		repo:       repo,
+		jwtManager: jwtManager,
+		passwords:  passwords,
+	}
+}
+
+func convertToOperatorResponse(operator *models.Operator) *dto.OperatorResponse {
+	return &dto.OperatorResponse{
+		UUID:      operator.UUID,
+		Username:  operator.Username,
+		FullName:  operator.FullName,
+		Role:      operator.Role,
+		CreatedAt: operator.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: operator.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *operatorService) Login(req dto.OperatorLoginRequest) (map[string]interface{}, error) {
+	operator, err := s.repo.GetByUsername(strings.TrimSpace(req.Username))
+	if err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+	ok, needsRehash, err := s.passwords.Verify(operator.Password, req.Password)
+	if err != nil || !ok {
+		return nil, errors.New("invalid credentials")
+	}
+	if needsRehash {
+		if hashed, err := s.passwords.Hash(req.Password); err == nil {
+			operator.Password = hashed
+			if err := s.repo.Update(operator); err != nil {
+				log.Printf("rehash password for operator %d: %v", operator.ID, err)
+			}
+		}
+	}
+	return s.issueTokens(operator)
+}
+
+func (s *operatorService) Refresh(rToken string) (map[string]interface{}, error) {
+	claims, err := s.jwtManager.ParseToken(rToken)
+	if claims == nil {
+		return nil, errors.New("invalid token")
+	}
+	if err != nil || claims.Type != enums.TokenTypeOperatorRefresh {
+		return nil, errors.New("invalid refresh token")
+	}
+	if err := redisProvider.FindValidOperatorRefresh(hashToken(rToken), claims.UserID); err != nil {
+		return nil, errors.New("refresh token revoked")
+	}
+	if err := redisProvider.RevokeOperatorRefresh(hashToken(rToken), claims.UserID); err != nil {
+		return nil, err
+	}
+
+	// reload so role changes and deletions take effect on refresh
+	operator, err := s.repo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("operator not found")
+	}
+	return s.issueTokens(operator)
+}
+
+func (s *operatorService) Logout(rToken string) error {
+	claims, err := s.jwtManager.ParseToken(rToken)
+	if claims == nil {
+		return errors.New("invalid token")
+	}
+	if err != nil || claims.Type != enums.TokenTypeOperatorRefresh {
+		return errors.New("invalid refresh token")
+	}
+	if err := redisProvider.IncreaseOperatorTokenVer(claims.UserID); err != nil {
+		return err
+	}
+	return redisProvider.RevokeAllByOperator(claims.UserID)
+}
+
+func (s *operatorService) issueTokens(operator *models.Operator) (map[string]interface{}, error) {
+	ver := redisProvider.GetOperatorTokenVer(operator.ID)
+
+	aToken, err := s.jwtManager.GenerateOperatorToken(operator.ID, operator.Username, operator.Role, enums.TokenTypeOperatorAccess, 900, ver)
+	if err != nil {
+		return nil, err
+	}
+	rToken, err := s.jwtManager.GenerateOperatorToken(operator.ID, operator.Username, operator.Role, enums.TokenTypeOperatorRefresh, 28800, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(rToken.ExpiresIn) * time.Second
+	if err := redisProvider.CreateOperatorRefresh(hashToken(rToken.Token), operator.ID, ttl); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"access_token":       aToken.Token,
+		"access_expires_in":  aToken.ExpiresIn,
+		"refresh_token":      rToken.Token,
+		"refresh_expires_in": rToken.ExpiresIn,
+		"role":               operator.Role,
+	}, nil
+}
+
+func (s *operatorService) Create(req dto.CreateOperatorRequest) (*dto.OperatorResponse, error) {
+	username := strings.TrimSpace(req.Username)
+	if _, err := s.repo.GetByUsername(username); err == nil {
+		return nil, errors.New("username already exists")
+	}
+	if !req.Role.IsValid() {
+		return nil, errors.New("invalid role")
+	}
+	hashed, err := s.passwords.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	operator := &models.Operator{
+		Username: username,
+		Password: hashed,
+		FullName: req.FullName,
+		Role:     req.Role,
+	}
+	operator.UUID = uuid.New().String()
+	operator.CreatedAt = time.Now()
+	if err := s.repo.Create(operator); err != nil {
+		return nil, err
+	}
+	return convertToOperatorResponse(operator), nil
+}
+
+func (s *operatorService) List(page, pageSize int, search string) ([]dto.OperatorResponse, int64, error) {
+	operators, total, err := s.repo.GetList(page, pageSize, strings.TrimSpace(search))
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]dto.OperatorResponse, 0, len(operators))
+	for _, o := range operators {
+		result = append(result, *convertToOperatorResponse(&o))
+	}
+	return result, total, nil
+}
+
+func (s *operatorService) Update(uuid string, req dto.UpdateOperatorRequest) (*dto.OperatorResponse, error) {
+	operator, err := s.repo.GetByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Role.IsValid() {
+		return nil, errors.New("invalid role")
+	}
+	roleChanged := operator.Role != req.Role
+	operator.FullName = req.FullName
+	operator.Role = req.Role
+	operator.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(operator); err != nil {
+		return nil, err
+	}
+	if roleChanged {
+		// the role is embedded in issued tokens, force a new login
+		if err := redisProvider.IncreaseOperatorTokenVer(operator.ID); err != nil {
+			return nil, err
+		}
+		if err := redisProvider.RevokeAllByOperator(operator.ID); err != nil {
+			return nil, err
+		}
+	}
+	return convertToOperatorResponse(operator), nil
+}
+
+func (s *operatorService) Delete(uuid string, currentOperatorID uint) error {
+	operator, err := s.repo.GetByUUID(uuid)
+	if err != nil {
+		return err
+	}
+	if operator.ID == currentOperatorID {
+		return errors.New("cannot delete yourself")
+	}
+	if err := s.repo.DeleteByID(operator.ID); err != nil {
+		return err
+	}
+	if err := redisProvider.IncreaseOperatorTokenVer(operator.ID); err != nil {
+		return err
+	}
+	return redisProvider.RevokeAllByOperator(operator.ID)
+}
+
+// EnsureBootstrapAdmin creates the first admin operator when the master DB
+// has none, so a fresh deployment is reachable at all.
+func (s *operatorService) EnsureBootstrapAdmin(username, password string) error {
+	total, err := s.repo.Count()
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return nil
+	}
+	if username == "" || password == "" {
+		return errors.New("no operator exists and PLATFORM_ADMIN_USERNAME/PLATFORM_ADMIN_PASSWORD are not set")
+	}
+	_, err = s.Create(dto.CreateOperatorRequest{
+		Username: username,
+		Password: password,
+		Role:     enums.OperatorRoleAdmin,
+	})
+	return err
+}
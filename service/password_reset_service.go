@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"golang-rest-user/enums"
+	"golang-rest-user/mailer"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/repository"
+	"golang-rest-user/security"
+	"golang-rest-user/utils"
+	"log"
+	"time"
+)
+
+const (
+	passwordResetTTL = 30 * time.Minute
+	// at most passwordResetMaxRequests mails per username and window
+	passwordResetWindow      = time.Hour
+	passwordResetMaxRequests = 3
+)
+
+var ErrPasswordResetInvalid = errors.New("password reset token is invalid or expired")
+
+// PasswordResetService lets users who forgot their password set a new one
+// through a single-use token sent by mail.
+type PasswordResetService interface {
+	Forgot(username string) error
+	Reset(token, password string) error
+}
+
+type passwordResetService struct {
+	tenantCode string
+	userRepo   repository.UserRepo
+	passwords  *security.PasswordManager
+	mailer     mailer.Mailer
+	publicURL  string
+}
+
+func NewPasswordResetService(tenantCode string, userRepo repository.UserRepo, passwords *security.PasswordManager, m mailer.Mailer, publicURL string) PasswordResetService {
+	return &passwordResetService{
+		tenantCode: tenantCode,
+		userRepo:   userRepo,
+		passwords:  passwords,
+		mailer:     m,
+		publicURL:  publicURL,
+	}
+}
+
+// Forgot mails a reset token if the user exists. It returns nil for unknown
+// users too, so the caller cannot tell them apart.
+func (s *passwordResetService) Forgot(username string) error {
+	requests, err := redisProvider.RecordUserTokenRequest(s.tenantCode, redisProvider.TokenPurposePasswordReset, username, passwordResetWindow)
+	if err != nil {
+		return err
+	}
+	if requests > passwordResetMaxRequests {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil
+	}
+
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	if err := redisProvider.CreateUserToken(s.tenantCode, redisProvider.TokenPurposePasswordReset, user.ID, hashToken(token), passwordResetTTL); err != nil {
+		return err
+	}
+
+	sendMail(s.mailer, mailer.Message{
+		To:      user.Username,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the following to reset your password within %d minutes:\n\n%s\n\nIf you did not ask for this, ignore this mail.\n",
+			user.FullName,
+			int(passwordResetTTL/time.Minute),
+			link(s.publicURL, "/reset-password", token),
+		),
+	})
+	return nil
+}
+
+// Reset consumes the token, sets the new password and ends every session of
+// the user. It also lifts a login lockout, the user has proven ownership.
+func (s *passwordResetService) Reset(token, password string) error {
+	userID, err := redisProvider.ConsumeUserToken(s.tenantCode, redisProvider.TokenPurposePasswordReset, hashToken(token))
+	if errors.Is(err, redisProvider.ErrTokenInvalid) {
+		return ErrPasswordResetInvalid
+	}
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return ErrPasswordResetInvalid
+	}
+
+	hashed, err := s.passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, hashed); err != nil {
+		return err
+	}
+	if err := redisProvider.IncreaseTokenVer(user.ID, s.tenantCode); err != nil {
+		return err
+	}
+	if err := redisProvider.RevokeAllByUser(s.tenantCode, user.ID); err != nil {
+		return err
+	}
+	if err := redisProvider.UnlockAccount(s.tenantCode, user.Username); err != nil {
+		log.Printf("unlock user %d after password reset: %v", user.ID, err)
+	}
+
+	emitSecurityEvent(SecurityEvent{
+		Type:       enums.SecurityEventPasswordReset,
+		TenantCode: s.tenantCode,
+		UserID:     user.ID,
+	})
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProvisionFunction runs the provisioning steps of a job. It is supplied by
+// the tenant provider, which owns the connection pools.
+type ProvisionFunction func(job *models.TenantProvisioning, tenant *models.Tenant)
+
+type ProvisioningService interface {
+	Start(tenant *models.Tenant) (*models.TenantProvisioning, error)
+	Get(uuid string) (*dto.ProvisioningResponse, error)
+	Retry(uuid string) (*dto.ProvisioningResponse, error)
+	StartStep(job *models.TenantProvisioning, name enums.ProvisioningStep) *models.TenantProvisioningStep
+	FinishStep(step *models.TenantProvisioningStep, err error)
+	Complete(job *models.TenantProvisioning, err error)
+	FailInterrupted() error
+	SetProvisionFunction(ProvisionFunction)
+}
+
+type provisioningService struct {
+	provisionFunction ProvisionFunction
+	repo              repository.ProvisioningRepo
+	tenantRepo        repository.TenantRepo
+}
+
+func NewProvisioningService(repo repository.ProvisioningRepo, tenantRepo repository.TenantRepo) ProvisioningService {
+	return &provisioningService{repo: repo, tenantRepo: tenantRepo}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func convertToProvisioningResponse(job *models.TenantProvisioning) *dto.ProvisioningResponse {
+	steps := make([]dto.ProvisioningStepResponse, 0, len(job.Steps))
+	for _, step := range job.Steps {
+		steps = append(steps, dto.ProvisioningStepResponse{
+			Attempt:    step.Attempt,
+			Name:       step.Name,
+			Status:     step.Status,
+			Error:      step.Error,
+			StartedAt:  step.StartedAt.Format(time.RFC3339),
+			FinishedAt: formatOptionalTime(step.FinishedAt),
+		})
+	}
+	return &dto.ProvisioningResponse{
+		UUID:       job.UUID,
+		TenantCode: job.TenantCode,
+		Status:     job.Status,
+		Attempt:    job.Attempt,
+		Error:      job.Error,
+		StartedAt:  formatOptionalTime(job.StartedAt),
+		FinishedAt: formatOptionalTime(job.FinishedAt),
+		Steps:      steps,
+	}
+}
+
+// Start records a pending job for the tenant and runs it in the background.
+func (s *provisioningService) Start(tenant *models.Tenant) (*models.TenantProvisioning, error) {
+	job := &models.TenantProvisioning{
+		TenantID:   tenant.ID,
+		TenantCode: tenant.Code,
+		Status:     enums.ProvisioningPending,
+		Attempt:    1,
+	}
+	job.UUID = uuid.New().String()
+	job.CreatedAt = time.Now()
+	if err := s.repo.Create(job); err != nil {
+		return nil, err
+	}
+	s.run(job, tenant)
+	return job, nil
+}
+
+func (s *provisioningService) run(job *models.TenantProvisioning, tenant *models.Tenant) {
+	if s.provisionFunction == nil {
+		log.Printf("no provision function set, tenant %s stays pending", tenant.Code)
+		return
+	}
+	jobCopy, tenantCopy := *job, *tenant
+	go s.provisionFunction(&jobCopy, &tenantCopy)
+}
+
+func (s *provisioningService) Get(uuid string) (*dto.ProvisioningResponse, error) {
+	job, err := s.repo.GetByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return convertToProvisioningResponse(job), nil
+}
+
+func (s *provisioningService) Retry(uuid string) (*dto.ProvisioningResponse, error) {
+	job, err := s.repo.GetByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != enums.ProvisioningFailed {
+		return nil, errors.New("only failed provisioning can be retried")
+	}
+	tenant, err := s.tenantRepo.GetByID(job.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tenantRepo.UpdateStatus(tenant.ID, enums.TenantStatusProvisioning); err != nil {
+		return nil, err
+	}
+	tenant.Status = enums.TenantStatusProvisioning
+
+	job.Attempt++
+	job.Status = enums.ProvisioningPending
+	job.Error = ""
+	job.StartedAt = nil
+	job.FinishedAt = nil
+	if err := s.repo.Update(job); err != nil {
+		return nil, err
+	}
+	s.run(job, tenant)
+	return convertToProvisioningResponse(job), nil
+}
+
+func (s *provisioningService) StartStep(job *models.TenantProvisioning, name enums.ProvisioningStep) *models.TenantProvisioningStep {
+	now := time.Now()
+	if job.Status != enums.ProvisioningRunning {
+		job.Status = enums.ProvisioningRunning
+		job.StartedAt = &now
+		if err := s.repo.Update(job); err != nil {
+			log.Printf("provisioning %s: %v", job.UUID, err)
+		}
+	}
+	step := &models.TenantProvisioningStep{
+		ProvisioningID: job.ID,
+		Attempt:        job.Attempt,
+		Name:           name,
+		Status:         enums.ProvisioningRunning,
+		StartedAt:      now,
+	}
+	if err := s.repo.CreateStep(step); err != nil {
+		log.Printf("provisioning %s step %s: %v", job.UUID, name, err)
+	}
+	return step
+}
+
+func (s *provisioningService) FinishStep(step *models.TenantProvisioningStep, err error) {
+	now := time.Now()
+	step.FinishedAt = &now
+	step.Status = enums.ProvisioningSucceeded
+	if err != nil {
+		step.Status = enums.ProvisioningFailed
+		step.Error = err.Error()
+	}
+	if err := s.repo.UpdateStep(step); err != nil {
+		log.Printf("provisioning step %s: %v", step.Name, err)
+	}
+}
+
+// Complete closes the job and moves the tenant to active or failed.
+func (s *provisioningService) Complete(job *models.TenantProvisioning, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = enums.ProvisioningSucceeded
+	status := enums.TenantStatusActive
+	if err != nil {
+		job.Status = enums.ProvisioningFailed
+		job.Error = err.Error()
+		status = enums.TenantStatusFailed
+	}
+	if err := s.repo.Update(job); err != nil {
+		log.Printf("provisioning %s: %v", job.UUID, err)
+	}
+	if err := s.tenantRepo.UpdateStatus(job.TenantID, status); err != nil {
+		log.Printf("provisioning %s: %v", job.UUID, err)
+	}
+}
+
+// FailInterrupted marks jobs that were still running when the process
+// stopped as failed, so they can be retried.
+func (s *provisioningService) FailInterrupted() error {
+	jobs, err := s.repo.GetUnfinished()
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		s.Complete(&jobs[i], errors.New("interrupted by restart"))
+	}
+	return nil
+}
+
+func (s *provisioningService) SetProvisionFunction(provisionFunction ProvisionFunction) {
+	s.provisionFunction = provisionFunction
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/redisProvider"
+	"log"
+	"time"
+)
+
+// SecurityEvent is something an operator should be able to audit or alert
+// on. Events go to the log and to the Redis stream security:events.
+type SecurityEvent struct {
+	Type       enums.SecurityEventType
+	TenantCode string
+	UserID     uint
+	Detail     map[string]string
+}
+
+func emitSecurityEvent(event SecurityEvent) {
+	log.Printf("security event %s tenant=%s user=%d %v", event.Type, event.TenantCode, event.UserID, event.Detail)
+
+	values := map[string]interface{}{
+		"type":        string(event.Type),
+		"tenant_code": event.TenantCode,
+		"user_id":     event.UserID,
+		"at":          time.Now().UTC().Format(time.RFC3339),
+	}
+	for k, v := range event.Detail {
+		values[k] = v
+	}
+	if err := redisProvider.AddSecurityEvent(values); err != nil {
+		log.Printf("security event %s: %v", event.Type, err)
+	}
+}
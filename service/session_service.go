@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/provider/redisProvider"
+	"sort"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionService manages a user's sessions, i.e. their refresh token
+// families, on behalf of the user themselves.
+type SessionService interface {
+	List(userID uint, currentSessionID string) ([]dto.SessionResponse, error)
+	Revoke(userID uint, sessionID string) error
+	RevokeOthers(userID uint, currentSessionID string) (int, error)
+}
+
+type sessionService struct {
+	tenantCode string
+}
+
+func NewSessionService(tenantCode string) SessionService {
+	return &sessionService{tenantCode: tenantCode}
+}
+
+func (s *sessionService) List(userID uint, currentSessionID string) ([]dto.SessionResponse, error) {
+	sessions, err := redisProvider.ListFamilies(s.tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+
+	result := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, dto.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+func (s *sessionService) Revoke(userID uint, sessionID string) error {
+	exists, err := redisProvider.FamilyExists(s.tenantCode, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrSessionNotFound
+	}
+	return redisProvider.RevokeFamily(s.tenantCode, userID, sessionID)
+}
+
+func (s *sessionService) RevokeOthers(userID uint, currentSessionID string) (int, error) {
+	return revokeOtherSessions(s.tenantCode, userID, currentSessionID)
+}
+
+func revokeOtherSessions(tenantCode string, userID uint, currentSessionID string) (int, error) {
+	sessions, err := redisProvider.ListFamilies(tenantCode, userID)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err := redisProvider.RevokeFamily(tenantCode, userID, session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
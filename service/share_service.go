@@ -6,6 +6,7 @@ import (
 	"golang-rest-user/enums"
 	"golang-rest-user/models"
 	"golang-rest-user/repository"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,15 +20,26 @@ type ShareService interface {
 	GetSharedUser(zoneUUID string, userID uint) ([]dto.UserResponse, error)
 }
 
+var (
+	// ErrShareInherited rejects a grant that adds nothing to what the user
+	// holds through a parent zone. The nearest grant wins, so it could only
+	// take rights away, e.g. from the owner of the tree.
+	ErrShareInherited = errors.New("user already has this access or more through a parent zone")
+	// ErrShareNarrowsSubzone rejects a grant that would leave one of the
+	// user's grants further down adding nothing to it, for the same reason.
+	ErrShareNarrowsSubzone = errors.New("user has a grant on a subzone that does not allow more than this, revoke it first")
+)
+
 type shareServiceImpl struct {
 	userZoneRepo repository.UserZoneRepo
 	zoneRepo     repository.ZoneRepo
 	userRepo     repository.UserRepo
+	authorizer   ZoneAuthorizer
 }
 
 func (s *shareServiceImpl) GetSharedUser(zoneUUID string, userID uint) ([]dto.UserResponse, error) {
 	var userResponse []dto.UserResponse
-	zone, err := s.checkOwnerPermission(zoneUUID, userID)
+	zone, _, err := s.checkShareManage(zoneUUID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -36,34 +48,46 @@ func (s *shareServiceImpl) GetSharedUser(zoneUUID string, userID uint) ([]dto.Us
 		return nil, err
 	}
 	for _, uz := range userZones {
-		user, _ := s.userRepo.GetByID(uz.UserID)
+		user, err := s.userRepo.GetByID(uz.UserID)
+		if err != nil {
+			continue
+		}
 		userResponse = append(userResponse, *convertToUserResponse(user))
 	}
 	return userResponse, nil
 }
 
 func (s *shareServiceImpl) UpdatePermission(zoneUUID, userUUID string, userID uint, req dto.ShareDTORequest) error {
-	zone, err := s.checkOwnerPermission(zoneUUID, userID)
+	zone, actions, err := s.checkShareManage(zoneUUID, userID)
 	if err != nil {
 		return err
 	}
-	user, _ := s.userRepo.GetByUUID(userUUID)
-	if !enums.IsValidUserPermission(string(req.Permission)) {
-		return errors.New("invalid permission")
+	if err := s.checkGrant(actions, req.Permission); err != nil {
+		return err
 	}
-	return s.userZoneRepo.UpdatePermission(user.ID, zone.ID, req.Permission)
+	userZone, err := s.sharedWith(zone, userUUID, actions)
+	if err != nil {
+		return err
+	}
+	if err := s.checkInherited(zone, userZone.UserID, req.Permission); err != nil {
+		return err
+	}
+	return s.userZoneRepo.UpdatePermission(userZone.UserID, zone.ID, req.Permission)
 }
 
 func (s *shareServiceImpl) ShareZone(userID uint, zoneUUID string, req dto.ShareDTORequest) (*dto.ShareDTOResponse, error) {
-	zone, err := s.checkOwnerPermission(zoneUUID, userID)
+	zone, actions, err := s.checkShareManage(zoneUUID, userID)
 	if err != nil {
 		return nil, err
 	}
 	if userID == req.UserID {
 		return nil, errors.New("sharing denied")
 	}
-	if !enums.IsValidUserPermission(string(req.Permission)) {
-		return nil, errors.New("invalid permission")
+	if err := s.checkGrant(actions, req.Permission); err != nil {
+		return nil, err
+	}
+	if err := s.checkInherited(zone, req.UserID, req.Permission); err != nil {
+		return nil, err
 	}
 	userZone := models.UserZone{
 		UserID:     req.UserID,
@@ -79,21 +103,133 @@ func (s *shareServiceImpl) ShareZone(userID uint, zoneUUID string, req dto.Share
 }
 
 func (s *shareServiceImpl) RevokeUser(zoneUUID, userUUID string, userID uint) (int64, error) {
-	user, _ := s.userRepo.GetByUUID(userUUID)
-	zone, err := s.checkOwnerPermission(zoneUUID, userID)
+	zone, actions, err := s.checkShareManage(zoneUUID, userID)
+	if err != nil {
+		return 0, err
+	}
+	userZone, err := s.sharedWith(zone, userUUID, actions)
 	if err != nil {
 		return 0, err
 	}
-	return s.userZoneRepo.Delete(user.ID, zone.ID)
+	return s.userZoneRepo.Delete(userZone.UserID, zone.ID)
 }
 
-func (s *shareServiceImpl) checkOwnerPermission(zoneUUID string, userID uint) (*models.Zone, error) {
-	zone, _ := s.zoneRepo.GetByUUID(zoneUUID)
-	curPermission, err := s.userZoneRepo.GetPermission(userID, zone.Path)
-	if err != nil || strings.Compare(curPermission, string(enums.UserOwner)) != 0 {
-		return nil, errors.New("permission denied")
+// checkShareManage loads the zone and makes sure the user may manage its
+// shares, returning the user's actions on it.
+func (s *shareServiceImpl) checkShareManage(zoneUUID string, userID uint) (*models.Zone, []enums.ZoneAction, error) {
+	zone, err := s.zoneRepo.GetByUUID(zoneUUID)
+	if err != nil {
+		return nil, nil, ErrZoneNotFound
+	}
+	actions, err := s.authorizer.Actions(userID, zone)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(actions, enums.ZoneActionShareManage) {
+		return nil, nil, ErrPermissionDenied
+	}
+	return zone, actions, nil
+}
+
+// checkGrant rejects unknown roles and roles that allow more than the
+// granting user may do.
+func (s *shareServiceImpl) checkGrant(actions []enums.ZoneAction, role enums.UserPermission) error {
+	ok, err := s.authorizer.CanGrant(actions, role)
+	if errors.Is(err, ErrZoneRoleNotFound) {
+		return errors.New("invalid permission")
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkInherited makes sure that, once the user holds role on the zone, every
+// grant of theirs it touches still allows strictly more than the grant it is
+// nested in: role compared with the nearest grant above the zone, and each
+// grant below the zone that will be nested in it compared with role.
+func (s *shareServiceImpl) checkInherited(zone *models.Zone, userID uint, role enums.UserPermission) error {
+	grants, err := s.userZoneRepo.ListGrants(userID)
+	if err != nil {
+		return err
+	}
+	roles := map[string]enums.UserPermission{zone.Path: role}
+	for _, g := range grants {
+		if g.Path != zone.Path {
+			roles[g.Path] = g.Permission
+		}
+	}
+	if err := s.checkNested(roles, zone.Path); err != nil {
+		return err
+	}
+	for path := range roles {
+		if path != zone.Path && strings.HasPrefix(path, zone.Path) && nearestGrantAbove(roles, path) == zone.Path {
+			if err := s.checkNested(roles, path); errors.Is(err, ErrShareInherited) {
+				return ErrShareNarrowsSubzone
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkNested returns ErrShareInherited unless the role held on path allows
+// strictly more than the one held on the nearest zone above it.
+func (s *shareServiceImpl) checkNested(roles map[string]enums.UserPermission, path string) error {
+	above := nearestGrantAbove(roles, path)
+	if above == "" {
+		return nil
+	}
+	inherited, err := s.authorizer.RoleActions(roles[above])
+	if err != nil || len(inherited) == 0 {
+		return err
+	}
+	granted, err := s.authorizer.RoleActions(roles[path])
+	if err != nil {
+		return err
+	}
+	for _, action := range inherited {
+		if !slices.Contains(granted, action) {
+			return ErrShareInherited
+		}
+	}
+	if len(granted) == len(inherited) {
+		return ErrShareInherited
+	}
+	return nil
+}
+
+// nearestGrantAbove returns the longest path in roles that is a strict
+// prefix of path, or "" if none is.
+func nearestGrantAbove(roles map[string]enums.UserPermission, path string) string {
+	nearest := ""
+	for p := range roles {
+		if p != path && strings.HasPrefix(path, p) && len(p) > len(nearest) {
+			nearest = p
+		}
+	}
+	return nearest
+}
+
+// sharedWith returns the grant of the user on the zone, provided the caller
+// could have granted it themselves.
+func (s *shareServiceImpl) sharedWith(zone *models.Zone, userUUID string, actions []enums.ZoneAction) (*models.UserZone, error) {
+	user, err := s.userRepo.GetByUUID(userUUID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	userZone, err := s.userZoneRepo.Get(user.ID, zone.ID)
+	if err != nil {
+		return nil, errors.New("zone is not shared with this user")
+	}
+	if err := s.checkGrant(actions, userZone.Permission); err != nil {
+		return nil, err
 	}
-	return zone, nil
+	return userZone, nil
 }
 
 func convertToShareDTOResponse(userZone *models.UserZone) *dto.ShareDTOResponse {
@@ -111,10 +247,12 @@ func NewShareService(
 	userZoneRepo repository.UserZoneRepo,
 	zoneRepo repository.ZoneRepo,
 	userRepo repository.UserRepo,
+	authorizer ZoneAuthorizer,
 ) ShareService {
 	return &shareServiceImpl{
 		userZoneRepo: userZoneRepo,
 		zoneRepo:     zoneRepo,
 		userRepo:     userRepo,
+		authorizer:   authorizer,
 	}
 }
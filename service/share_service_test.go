@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"testing"
+)
+
+func TestShareZoneInheritedAccess(t *testing.T) {
+	const (
+		owner   uint = 1
+		manager uint = 2
+		other   uint = 3
+	)
+	tests := []struct {
+		name    string
+		prepare func(s *zoneStore)
+		target  uint
+		role    enums.UserPermission
+		update  bool
+		wantErr error
+	}{
+		{
+			name:    "owner of the tree cannot be narrowed on a subzone",
+			target:  owner,
+			role:    enums.UserViewer,
+			wantErr: ErrShareInherited,
+		},
+		{
+			name:    "same role as on the parent adds nothing",
+			prepare: func(s *zoneStore) { s.grant(other, s.zones[1], enums.UserViewer) },
+			target:  other,
+			role:    enums.UserViewer,
+			wantErr: ErrShareInherited,
+		},
+		{
+			name:    "a wider role than on the parent is shared",
+			prepare: func(s *zoneStore) { s.grant(other, s.zones[1], enums.UserViewer) },
+			target:  other,
+			role:    "share_admin",
+		},
+		{
+			name:   "user without access above is shared",
+			target: other,
+			role:   enums.UserViewer,
+		},
+		{
+			name: "existing grant cannot be narrowed below the parent's",
+			prepare: func(s *zoneStore) {
+				s.grant(other, s.zones[1], enums.UserViewer)
+				s.grant(other, s.zones[2], "share_admin")
+			},
+			target:  other,
+			role:    enums.UserViewer,
+			update:  true,
+			wantErr: ErrShareInherited,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newZoneStore()
+			s.addRole("share_admin", enums.ZoneActionRead, enums.ZoneActionShareManage)
+			root := s.addZone(1, nil)
+			sub := s.addZone(2, root)
+			for _, id := range []uint{owner, manager, other} {
+				s.addUser(id)
+			}
+			s.grant(owner, root, enums.UserOwner)
+			s.grant(manager, sub, "share_admin")
+			if tt.prepare != nil {
+				tt.prepare(s)
+			}
+			_, shares := s.services()
+
+			var err error
+			if tt.update {
+				err = shares.UpdatePermission(sub.UUID, s.users[tt.target].UUID, manager, dto.ShareDTORequest{Permission: tt.role})
+			} else {
+				_, err = shares.ShareZone(manager, sub.UUID, dto.ShareDTORequest{UserID: tt.target, Permission: tt.role})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.target == owner && s.grantOf(owner, sub.ID) != nil {
+				t.Fatal("a grant narrowing the owner's access was stored")
+			}
+		})
+	}
+}
+
+func TestShareZoneNarrowsSubzoneGrant(t *testing.T) {
+	const (
+		owner uint = 1
+		other uint = 2
+	)
+	tests := []struct {
+		name    string
+		prepare func(s *zoneStore)
+		role    enums.UserPermission
+		update  bool
+		wantErr error
+	}{
+		{
+			name:    "a subzone grant no wider than the new one",
+			prepare: func(s *zoneStore) { s.grant(other, s.zones[2], enums.UserViewer) },
+			role:    enums.UserEditor,
+			wantErr: ErrShareNarrowsSubzone,
+		},
+		{
+			name:    "a deeper subzone grant no wider than the new one",
+			prepare: func(s *zoneStore) { s.grant(other, s.zones[3], enums.UserViewer) },
+			role:    enums.UserViewer,
+			wantErr: ErrShareNarrowsSubzone,
+		},
+		{
+			name:    "a wider subzone grant is kept",
+			prepare: func(s *zoneStore) { s.grant(other, s.zones[2], enums.UserEditor) },
+			role:    enums.UserViewer,
+		},
+		{
+			name: "widening a grant above a subzone grant",
+			prepare: func(s *zoneStore) {
+				s.grant(other, s.zones[1], enums.UserViewer)
+				s.grant(other, s.zones[2], "share_admin")
+			},
+			role:    enums.UserEditor,
+			update:  true,
+			wantErr: ErrShareNarrowsSubzone,
+		},
+		{
+			name: "a subzone grant nested in another one is compared with that one",
+			prepare: func(s *zoneStore) {
+				s.grant(other, s.zones[2], enums.UserEditor)
+				s.grant(other, s.zones[3], enums.UserOwner)
+			},
+			role: enums.UserViewer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newZoneStore()
+			s.addRole("share_admin", enums.ZoneActionRead, enums.ZoneActionShareManage)
+			root := s.addZone(1, nil)
+			s.addZone(3, s.addZone(2, root))
+			s.addUser(owner)
+			s.addUser(other)
+			s.grant(owner, root, enums.UserOwner)
+			tt.prepare(s)
+			_, shares := s.services()
+
+			var err error
+			if tt.update {
+				err = shares.UpdatePermission(root.UUID, s.users[other].UUID, owner, dto.ShareDTORequest{Permission: tt.role})
+			} else {
+				_, err = shares.ShareZone(owner, root.UUID, dto.ShareDTORequest{UserID: other, Permission: tt.role})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
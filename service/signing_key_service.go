@@ -0,0 +1,149 @@
+package service
+
+import (
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"golang-rest-user/security"
+	"golang-rest-user/utils"
+	"log"
+	"time"
+)
+
+// SigningKeyService stores the JWT key ring in the master DB and is the
+// security.KeyStore of the JWT manager.
+type SigningKeyService interface {
+	LoadKeys() ([]*security.SigningKey, error)
+	List() ([]dto.SigningKeyResponse, error)
+	Rotate(algorithm string) (*dto.SigningKeyResponse, error)
+	EnsureActive(algorithm string) error
+}
+
+type signingKeyService struct {
+	repo repository.SigningKeyRepo
+	// retention is how long a retired key keeps verifying, at least the
+	// lifetime of the longest token it may have signed.
+	retention time.Duration
+}
+
+func NewSigningKeyService(repo repository.SigningKeyRepo, retention time.Duration) SigningKeyService {
+	return &signingKeyService{repo: repo, retention: retention}
+}
+
+func convertToSigningKeyResponse(key *models.SigningKey) *dto.SigningKeyResponse {
+	return &dto.SigningKeyResponse{
+		Kid:       key.Kid,
+		Algorithm: key.Algorithm,
+		Status:    key.Status,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+		RetiredAt: formatOptionalTime(key.RetiredAt),
+	}
+}
+
+func (s *signingKeyService) LoadKeys() ([]*security.SigningKey, error) {
+	rows, err := s.repo.ListVerifiable(time.Now().Add(-s.retention))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*security.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		key, err := s.decode(&row)
+		if err != nil {
+			// one broken row must not take down every other key
+			log.Printf("signing key %s: %v", row.Kid, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *signingKeyService) decode(row *models.SigningKey) (*security.SigningKey, error) {
+	public, err := security.DecodePublicKey(row.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key := &security.SigningKey{
+		ID:        row.Kid,
+		Algorithm: row.Algorithm,
+		Public:    public,
+		Active:    row.Status == enums.SigningKeyActive,
+		CreatedAt: row.CreatedAt,
+	}
+	if !key.Active {
+		return key, nil
+	}
+	decrypted, err := utils.AESGCMDecrypt(row.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if key.Private, err = security.DecodePrivateKey(decrypted); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *signingKeyService) List() ([]dto.SigningKeyResponse, error) {
+	rows, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.SigningKeyResponse, 0, len(rows))
+	for i := range rows {
+		result = append(result, *convertToSigningKeyResponse(&rows[i]))
+	}
+	return result, nil
+}
+
+// Rotate creates a new active key and retires the current one. Tokens that
+// were signed with the old key stay valid until they expire.
+func (s *signingKeyService) Rotate(algorithm string) (*dto.SigningKeyResponse, error) {
+	if !security.IsAsymmetric(algorithm) {
+		return nil, security.ErrUnsupportedAlgorithm
+	}
+	key, err := security.GenerateSigningKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	privatePEM, err := security.EncodePrivateKey(key.Private)
+	if err != nil {
+		return nil, err
+	}
+	encryptedPrivate, err := utils.AESGCMEncrypt(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+	publicPEM, err := security.EncodePublicKey(key.Public)
+	if err != nil {
+		return nil, err
+	}
+	row := &models.SigningKey{
+		Kid:        key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: encryptedPrivate,
+		PublicKey:  publicPEM,
+		Status:     enums.SigningKeyActive,
+		CreatedAt:  key.CreatedAt,
+	}
+	if err := s.repo.Rotate(row); err != nil {
+		return nil, err
+	}
+	return convertToSigningKeyResponse(row), nil
+}
+
+// EnsureActive creates the first key on a fresh deployment, or a new one
+// when the configured algorithm changed.
+func (s *signingKeyService) EnsureActive(algorithm string) error {
+	keys, err := s.LoadKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.Active && k.Private != nil && k.Algorithm == algorithm {
+			return nil
+		}
+	}
+	_, err = s.Rotate(algorithm)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"golang-rest-user/enums"
 	"golang-rest-user/models"
+	"golang-rest-user/provider/redisProvider"
 	"golang-rest-user/utils"
 	"regexp"
 	"strings"
@@ -11,29 +12,39 @@ import (
 	"golang-rest-user/dto"
 	"golang-rest-user/repository"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type CallBackFunction func(mode enums.HandleTenant, tenantCode string, tenant *models.Tenant)
 
 var dbnameRegex = regexp.MustCompile("^[a-z0-9_]{1,64}$")
 
+var ErrTenantProvisioning = errors.New("tenant is still provisioning")
+
 type TenantService interface {
-	Create(dto.CreateTenantRequest) (*dto.TenantResponse, error)
+	Create(dto.CreateTenantRequest) (*dto.CreateTenantResponse, error)
 	GetByTenantCode(string) (*dto.TenantResponse, error)
 	List(page, pageSize int, search string) ([]dto.TenantResponse, int64, error)
 	ListAllTenantConnect() ([]models.Tenant, error)
+	GetTenantConnect(tenantCode string) (*models.Tenant, error)
+	GetSettings(tenantCode string) (*models.TenantSettings, error)
+	UpdateSettings(tenantCode string, settings models.TenantSettings) (*models.TenantSettings, error)
 	Update(tenantCode string, req dto.UpdateTenantRequest) (*dto.TenantResponse, error)
 	Delete(string) error
+	Suspend(tenantCode string) (*dto.TenantResponse, error)
+	Resume(tenantCode string) (*dto.TenantResponse, error)
 	SetCallBackFunction(CallBackFunction)
 }
 
 type tenantService struct {
 	callBackFunction CallBackFunction
 	repo             repository.TenantRepo
+	provisioning     ProvisioningService
 }
 
-func NewTenantService(r repository.TenantRepo) TenantService {
-	return &tenantService{repo: r}
+func NewTenantService(r repository.TenantRepo, provisioning ProvisioningService) TenantService {
+	return &tenantService{repo: r, provisioning: provisioning}
 }
 
 func convertToTenantResponse(tenant *models.Tenant) *dto.TenantResponse {
@@ -54,7 +65,9 @@ func isValidDBName(name string) bool {
 	return dbnameRegex.MatchString(name)
 }
 
-func (s *tenantService) Create(req dto.CreateTenantRequest) (*dto.TenantResponse, error) {
+// Create saves the tenant as provisioning and starts creating its database in
+// the background. Progress is tracked under the returned provisioning ID.
+func (s *tenantService) Create(req dto.CreateTenantRequest) (*dto.CreateTenantResponse, error) {
 	// check tenant code existing
 	if _, err := s.repo.GetByTenantCode(req.Code); err == nil {
 		return nil, errors.New("tenant code already exists")
@@ -85,20 +98,21 @@ func (s *tenantService) Create(req dto.CreateTenantRequest) (*dto.TenantResponse
 		DBHost: req.DBHost,
 		DBPort: req.DBPort,
 		DBName: req.DBName,
+		Status: enums.TenantStatusProvisioning,
 	}
+	tenant.UUID = uuid.New().String()
 	tenant.CreatedAt = time.Now()
-	if s.callBackFunction != nil {
-		go func() {
-			s.callBackFunction(enums.AddTenantConnect, tenant.Code, tenant)
-		}()
-	}
 	if err := s.repo.Create(tenant); err != nil {
-		go func() {
-			s.callBackFunction(enums.DropTenantConnect, tenant.Code, tenant)
-		}()
 		return nil, err
 	}
-	return convertToTenantResponse(tenant), nil
+	job, err := s.provisioning.Start(tenant)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CreateTenantResponse{
+		Tenant:         convertToTenantResponse(tenant),
+		ProvisioningID: job.UUID,
+	}, nil
 }
 
 func (s *tenantService) GetByTenantCode(tenantCode string) (*dto.TenantResponse, error) {
@@ -131,11 +145,18 @@ func (s *tenantService) ListAllTenantConnect() ([]models.Tenant, error) {
 	return tenants, nil
 }
 
+func (s *tenantService) GetTenantConnect(tenantCode string) (*models.Tenant, error) {
+	return s.repo.GetByTenantCode(strings.TrimSpace(strings.ToLower(tenantCode)))
+}
+
 func (s *tenantService) Update(tenantCode string, req dto.UpdateTenantRequest) (*dto.TenantResponse, error) {
 	tenant, err := s.repo.GetByTenantCode(tenantCode)
 	if err != nil {
 		return nil, err
 	}
+	if tenant.Status == enums.TenantStatusProvisioning {
+		return nil, ErrTenantProvisioning
+	}
 	//AESGCMDecrypt old db user
 	oldDBUser, err := utils.AESGCMDecrypt(tenant.DBUser)
 	if err != nil {
@@ -176,16 +197,15 @@ func (s *tenantService) Update(tenantCode string, req dto.UpdateTenantRequest) (
 	tenant.DBPort = req.DBPort
 	tenant.UpdatedAt = time.Now().UTC()
 
-	if s.callBackFunction != nil {
-		go func() {
-			s.callBackFunction(enums.EditTenantConnect, tenant.Code, tenant)
-		}()
-	}
 	if err := s.repo.Update(tenant); err != nil {
+		return nil, err
+	}
+	// a failed tenant only comes up through a provisioning retry, which
+	// also records its status
+	if s.callBackFunction != nil && tenant.Status != enums.TenantStatusFailed {
 		go func() {
-			s.callBackFunction(enums.DropTenantConnect, tenant.Code, tenant)
+			s.callBackFunction(enums.EditTenantConnect, tenant.Code, tenant)
 		}()
-		return nil, err
 	}
 
 	return convertToTenantResponse(tenant), nil
@@ -203,14 +223,106 @@ func (s *tenantService) Delete(tenantCode string) error {
 	if err != nil {
 		return err
 	}
+	if tenant.Status == enums.TenantStatusProvisioning {
+		return ErrTenantProvisioning
+	}
+	if err := s.repo.DeleteByID(tenant.BaseModel.ID); err != nil {
+		return err
+	}
+	if s.callBackFunction != nil {
+		s.callBackFunction(enums.DeleteTenantConnect, tenant.Code, tenant)
+	}
+	return nil
+}
+
+func (s *tenantService) Suspend(tenantCode string) (*dto.TenantResponse, error) {
+	tenant, err := s.repo.GetByTenantCode(tenantCode)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status != enums.TenantStatusActive {
+		return nil, errors.New("only active tenants can be suspended")
+	}
+	tenant.Status = enums.TenantStatusInactive
+	tenant.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(tenant); err != nil {
+		return nil, err
+	}
+	if s.callBackFunction != nil {
+		s.callBackFunction(enums.SuspendTenantConnect, tenant.Code, tenant)
+	}
+	if err := redisProvider.RevokeAllByTenant(tenant.Code); err != nil {
+		return nil, err
+	}
+	return convertToTenantResponse(tenant), nil
+}
+
+func (s *tenantService) Resume(tenantCode string) (*dto.TenantResponse, error) {
+	tenant, err := s.repo.GetByTenantCode(tenantCode)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status != enums.TenantStatusInactive {
+		return nil, errors.New("tenant is not suspended")
+	}
+	tenant.Status = enums.TenantStatusActive
+	tenant.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(tenant); err != nil {
+		return nil, err
+	}
 	if s.callBackFunction != nil {
 		go func() {
-			s.callBackFunction(enums.DeleteTenantConnect, tenant.Code, tenant)
+			s.callBackFunction(enums.ResumeTenantConnect, tenant.Code, tenant)
 		}()
 	}
-	return s.repo.DeleteByID(tenant.BaseModel.ID)
+	return convertToTenantResponse(tenant), nil
 }
 
 func (s *tenantService) SetCallBackFunction(callBackFunction CallBackFunction) {
 	s.callBackFunction = callBackFunction
 }
+
+func (s *tenantService) GetSettings(tenantCode string) (*models.TenantSettings, error) {
+	tenant, err := s.repo.GetByTenantCode(tenantCode)
+	if err != nil {
+		return nil, err
+	}
+	return &tenant.Settings, nil
+}
+
+func (s *tenantService) UpdateSettings(tenantCode string, settings models.TenantSettings) (*models.TenantSettings, error) {
+	tenant, err := s.repo.GetByTenantCode(tenantCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSettings(settings); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSettings(tenant.ID, settings); err != nil {
+		return nil, err
+	}
+	tenant.Settings = settings
+	if s.callBackFunction != nil {
+		s.callBackFunction(enums.SettingsTenantConnect, tenant.Code, tenant)
+	}
+	return &tenant.Settings, nil
+}
+
+func validateSettings(settings models.TenantSettings) error {
+	login := settings.Login
+	for _, v := range []int{
+		login.MaxFailures,
+		login.FailureWindowSeconds,
+		login.LockoutSeconds,
+		login.MaxFailuresPerIP,
+		login.DelayAfterFailures,
+		login.DelayBaseSeconds,
+		login.DelayMaxSeconds,
+		settings.Zones.TrashRetentionDays,
+	} {
+		if v < 0 {
+			return errors.New("invalid settings: values must not be negative")
+		}
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"errors"
 	"fmt"
-	"golang-rest-user/utils"
+	"golang-rest-user/enums"
+	"golang-rest-user/provider/redisProvider"
+	"golang-rest-user/security"
+	"log"
 	"strings"
 	"time"
 
@@ -16,30 +20,55 @@ import (
 type UserService interface {
 	Create(dto.CreateUserRequest) (*dto.UserResponse, error)
 	GetByUUID(string) (*dto.UserResponse, error)
+	GetByID(id uint) (*dto.UserResponse, error)
+	GetByUsername(username string) (*dto.UserResponse, error)
 	List(page, pageSize int, search string) ([]dto.UserResponse, int64, error)
 	Update(uuid string, req dto.UpdateUserRequest) (*dto.UserResponse, error)
+	// SetRole changes the tenant role; the user's access tokens are revoked
+	// so the new role applies from their next refresh.
+	SetRole(uuid string, role enums.TenantRole) (*dto.UserResponse, error)
+	UpdateByID(id uint, req dto.UpdateUserRequest) (*dto.UserResponse, error)
+	// ChangePassword checks the current password, sets the new one and ends
+	// every session of the user but the current one. Wrong passwords count
+	// as failed logins.
+	ChangePassword(id uint, currentSessionID string, client dto.ClientInfo, req dto.ChangePasswordRequest) error
 	DeleteMany([]string) (int64, error)
+	CountLegacyPasswords() (legacy int64, total int64, err error)
+	ResetPassword(username, password string) error
+	Unlock(uuid string) (*dto.UserResponse, error)
+	RevokeSessions(username string) error
 }
 
+var (
+	ErrWrongPassword = errors.New("current password is incorrect")
+	ErrInvalidRole   = errors.New("invalid role")
+	ErrLastAdmin     = repository.ErrLastAdmin
+)
+
 type userService struct {
 	tenantCode string
 	repo       repository.UserRepo
+	passwords  *security.PasswordManager
+	settings   SettingsFunc
 }
 
-func NewUserService(tenantCode string, r repository.UserRepo) UserService {
-	return &userService{repo: r, tenantCode: tenantCode}
+func NewUserService(tenantCode string, r repository.UserRepo, passwords *security.PasswordManager, settings SettingsFunc) UserService {
+	return &userService{repo: r, tenantCode: tenantCode, passwords: passwords, settings: settings}
 }
 
 func convertToUserResponse(user *models.User) *dto.UserResponse {
 	return &dto.UserResponse{
-		ID:        user.ID,
-		UUID:      user.UUID,
-		Username:  user.Username,
-		FullName:  user.FullName,
-		Phone:     user.Phone,
-		Position:  user.Position,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+		ID:            user.ID,
+		UUID:          user.UUID,
+		Username:      user.Username,
+		FullName:      user.FullName,
+		Phone:         user.Phone,
+		Position:      user.Position,
+		Role:          string(user.Role),
+		EmailVerified: user.EmailVerified,
+		MFAEnabled:    user.MFAEnabled,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -49,20 +78,32 @@ func (s *userService) Create(req dto.CreateUserRequest) (*dto.UserResponse, erro
 		return nil, fmt.Errorf("username already exists")
 	}
 
-	passEncrypted, err := utils.AESGCMEncrypt(req.Password)
+	role := enums.TenantRole(req.Role)
+	if role == "" {
+		role = enums.TenantRoleMember
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	passHashed, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &models.User{
 		Username: req.Username,
-		Password: passEncrypted,
+		Password: passHashed,
 		FullName: req.FullName,
 		Phone:    req.Phone,
 		Position: req.Position,
+		Role:     role,
 	}
 	user.UUID = uuid.New().String()
 	user.CreatedAt = time.Now()
+	// created by an admin, who vouches for the address
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &user.CreatedAt
 
 	if err := s.repo.Create(user); err != nil {
 		return nil, err
@@ -75,7 +116,31 @@ func (s *userService) GetByUUID(uuid string) (*dto.UserResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return convertToUserResponse(user), nil
+	return s.withLockout(user)
+}
+
+func (s *userService) GetByID(id uint) (*dto.UserResponse, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLockout(user)
+}
+
+func (s *userService) GetByUsername(username string) (*dto.UserResponse, error) {
+	user, err := s.repo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLockout(user)
+}
+
+func (s *userService) withLockout(user *models.User) (*dto.UserResponse, error) {
+	result := []dto.UserResponse{*convertToUserResponse(user)}
+	if err := s.fillLockout(result); err != nil {
+		return nil, err
+	}
+	return &result[0], nil
 }
 
 func (s *userService) List(page, pageSize int, search string) ([]dto.UserResponse, int64, error) {
@@ -88,14 +153,60 @@ func (s *userService) List(page, pageSize int, search string) ([]dto.UserRespons
 	for _, u := range users {
 		result = append(result, *convertToUserResponse(&u))
 	}
+	if err := s.fillLockout(result); err != nil {
+		return nil, 0, err
+	}
 	return result, total, nil
 }
 
+// fillLockout sets the login lockout state on the responses.
+func (s *userService) fillLockout(users []dto.UserResponse) error {
+	usernames := make([]string, len(users))
+	for i := range users {
+		usernames[i] = users[i].Username
+	}
+	locks, err := redisProvider.AccountLocks(s.tenantCode, usernames)
+	if err != nil {
+		return err
+	}
+	for i, d := range locks {
+		if d > 0 {
+			users[i].Locked = true
+			users[i].LockedUntil = time.Now().Add(d).UTC().Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+// Unlock lifts a login lockout and forgets the user's failed attempts.
+func (s *userService) Unlock(uuid string) (*dto.UserResponse, error) {
+	user, err := s.repo.GetByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := redisProvider.UnlockAccount(s.tenantCode, user.Username); err != nil {
+		return nil, err
+	}
+	return convertToUserResponse(user), nil
+}
+
 func (s *userService) Update(uuid string, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	user, err := s.repo.GetByUUID(uuid)
 	if err != nil {
 		return nil, err
 	}
+	return s.update(user, req)
+}
+
+func (s *userService) UpdateByID(id uint, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(user, req)
+}
+
+func (s *userService) update(user *models.User, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	user.FullName = req.FullName
 	user.Phone = req.Phone
 	user.Position = req.Position
@@ -121,3 +232,95 @@ func (s *userService) DeleteMany(uuids []string) (int64, error) {
 	}
 	return s.repo.DeleteByIDs(ids)
 }
+
+func (s *userService) SetRole(uuid string, role enums.TenantRole) (*dto.UserResponse, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	user, err := s.repo.GetByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return convertToUserResponse(user), nil
+	}
+	if err := s.repo.UpdateRole(user.ID, role); err != nil {
+		return nil, err
+	}
+	// the role is embedded in access tokens
+	if err := redisProvider.IncreaseTokenVer(user.ID, s.tenantCode); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return convertToUserResponse(user), nil
+}
+
+func (s *userService) CountLegacyPasswords() (legacy int64, total int64, err error) {
+	return s.repo.CountLegacyPasswords()
+}
+
+// ResetPassword sets a new password without knowing the old one and ends
+// every session of the user.
+func (s *userService) ResetPassword(username, password string) error {
+	user, err := s.repo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	hashed, err := s.passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(user.ID, hashed); err != nil {
+		return err
+	}
+	return s.revokeSessions(user.ID)
+}
+
+func (s *userService) ChangePassword(id uint, currentSessionID string, client dto.ClientInfo, req dto.ChangePasswordRequest) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	loginSettings := s.settings().Login.WithDefaults()
+	if err := checkLoginAllowed(s.tenantCode, user.Username, client.IP, loginSettings); err != nil {
+		return err
+	}
+	ok, _, err := s.passwords.Verify(user.Password, req.CurrentPassword)
+	if err != nil || !ok {
+		recordLoginFailure(s.tenantCode, user.Username, client.IP, loginSettings)
+		return ErrWrongPassword
+	}
+	hashed, err := s.passwords.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(user.ID, hashed); err != nil {
+		return err
+	}
+	if err := redisProvider.ClearLoginFailures(s.tenantCode, user.Username); err != nil {
+		log.Printf("clear login failures for user %d: %v", user.ID, err)
+	}
+	emitSecurityEvent(SecurityEvent{
+		Type:       enums.SecurityEventPasswordChanged,
+		TenantCode: s.tenantCode,
+		UserID:     user.ID,
+		Detail:     map[string]string{"ip": client.IP},
+	})
+	_, err = revokeOtherSessions(s.tenantCode, user.ID, currentSessionID)
+	return err
+}
+
+func (s *userService) RevokeSessions(username string) error {
+	user, err := s.repo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	return s.revokeSessions(user.ID)
+}
+
+func (s *userService) revokeSessions(userID uint) error {
+	if err := redisProvider.IncreaseTokenVer(userID, s.tenantCode); err != nil {
+		return err
+	}
+	return redisProvider.RevokeAllByUser(s.tenantCode, userID)
+}
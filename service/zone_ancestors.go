@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"strconv"
+	"strings"
+)
+
+func (s *zoneServiceImpl) GetAncestors(uuid string, userID uint) ([]dto.ZoneDTOResponse, error) {
+	zone, err := s.authorizedZone(uuid, userID, enums.ZoneActionRead)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.visibleAncestors(zone, userID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.ZoneDTOResponse, 0, len(ancestors))
+	for i := range ancestors {
+		responses = append(responses, *convertToZoneDTOResponse(&ancestors[i]))
+	}
+	return responses, nil
+}
+
+// visibleAncestors resolves the zone's path into its ancestors, root first,
+// leaving out those the user cannot read. A zone shared below the root keeps
+// the zones above it hidden.
+func (s *zoneServiceImpl) visibleAncestors(zone *models.Zone, userID uint) ([]models.Zone, error) {
+	ids := ancestorIDs(zone.Path)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	ancestors, err := s.zoneRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	visible := ancestors[:0]
+	for _, ancestor := range ancestors {
+		err := s.authorizer.Authorize(userID, &ancestor, enums.ZoneActionRead)
+		if errors.Is(err, ErrPermissionDenied) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		visible = append(visible, ancestor)
+	}
+	return visible, nil
+}
+
+func (s *zoneServiceImpl) breadcrumb(zone *models.Zone, userID uint) ([]dto.ZoneBreadcrumb, error) {
+	ancestors, err := s.visibleAncestors(zone, userID)
+	if err != nil {
+		return nil, err
+	}
+	crumbs := make([]dto.ZoneBreadcrumb, 0, len(ancestors)+1)
+	for _, z := range append(ancestors, *zone) {
+		crumbs = append(crumbs, dto.ZoneBreadcrumb{UUID: z.UUID, Name: z.Name, Type: z.Type, Level: z.Level})
+	}
+	return crumbs, nil
+}
+
+// ancestorIDs returns the IDs in a materialized path such as "1/5/9/",
+// without the zone's own ID.
+func ancestorIDs(path string) []uint {
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	ids := make([]uint, 0, len(parts))
+	for _, part := range parts[:len(parts)-1] {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"slices"
+)
+
+var (
+	ErrZoneNotFound     = errors.New("zone not found")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// ZoneAuthorizer decides what a user may do on a zone: the role they hold on
+// the zone or its nearest ancestor, expanded to that role's actions. Zone and
+// share services check every operation through it.
+type ZoneAuthorizer interface {
+	Actions(userID uint, zone *models.Zone) ([]enums.ZoneAction, error)
+	// RoleActions is what a role allows, nothing for an unknown role.
+	RoleActions(role enums.UserPermission) ([]enums.ZoneAction, error)
+	// Authorize returns ErrPermissionDenied unless the user may do action.
+	Authorize(userID uint, zone *models.Zone, action enums.ZoneAction) error
+	// CanGrant reports whether a user holding actions may hand out role,
+	// i.e. whether the role allows nothing the user is not allowed.
+	CanGrant(actions []enums.ZoneAction, role enums.UserPermission) (bool, error)
+}
+
+type zoneAuthorizer struct {
+	userZoneRepo repository.UserZoneRepo
+	roles        ZoneRoleService
+}
+
+func NewZoneAuthorizer(userZoneRepo repository.UserZoneRepo, roles ZoneRoleService) ZoneAuthorizer {
+	return &zoneAuthorizer{userZoneRepo: userZoneRepo, roles: roles}
+}
+
+func (a *zoneAuthorizer) Actions(userID uint, zone *models.Zone) ([]enums.ZoneAction, error) {
+	role, err := a.userZoneRepo.GetPermission(userID, zone.Path)
+	if err != nil || role == "" {
+		return nil, err
+	}
+	return a.RoleActions(enums.UserPermission(role))
+}
+
+func (a *zoneAuthorizer) RoleActions(role enums.UserPermission) ([]enums.ZoneAction, error) {
+	actions, err := a.roles.Actions(role)
+	if errors.Is(err, ErrZoneRoleNotFound) {
+		return nil, nil
+	}
+	return actions, err
+}
+
+func (a *zoneAuthorizer) Authorize(userID uint, zone *models.Zone, action enums.ZoneAction) error {
+	actions, err := a.Actions(userID, zone)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(actions, action) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+func (a *zoneAuthorizer) CanGrant(actions []enums.ZoneAction, role enums.UserPermission) (bool, error) {
+	granted, err := a.roles.Actions(role)
+	if err != nil {
+		return false, err
+	}
+	for _, action := range granted {
+		if !slices.Contains(actions, action) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
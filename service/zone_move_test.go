@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/enums"
+	"testing"
+)
+
+// The tree of the move tests, owned by user 1:
+//
+//	1
+//	├── 2
+//	│   └── 3
+//	│       └── 4
+//	└── 5
+func newMoveStore() *zoneStore {
+	s := newZoneStore()
+	root := s.addZone(1, nil)
+	a := s.addZone(2, root)
+	b := s.addZone(3, a)
+	s.addZone(4, b)
+	s.addZone(5, root)
+	s.grant(1, root, enums.UserOwner)
+	return s
+}
+
+func TestMoveZone(t *testing.T) {
+	tests := []struct {
+		name      string
+		prepare   func(s *zoneStore)
+		userID    uint
+		zoneID    uint
+		parentID  uint
+		wantErr   error
+		wantMoved int64
+		// wantPaths are checked after a successful move
+		wantPaths map[uint]string
+	}{
+		{
+			name: "subtree under a sibling", userID: 1, zoneID: 2, parentID: 5,
+			wantMoved: 3,
+			wantPaths: map[uint]string{2: "1/5/2/", 3: "1/5/2/3/", 4: "1/5/2/3/4/", 5: "1/5/"},
+		},
+		{
+			name: "leaf up to the root", userID: 1, zoneID: 4, parentID: 1,
+			wantMoved: 1,
+			wantPaths: map[uint]string{4: "1/4/", 3: "1/2/3/"},
+		},
+		{name: "into itself", userID: 1, zoneID: 2, parentID: 2, wantErr: ErrZoneMoveCycle},
+		{name: "into its child", userID: 1, zoneID: 2, parentID: 3, wantErr: ErrZoneMoveCycle},
+		{name: "into a deeper descendant", userID: 1, zoneID: 2, parentID: 4, wantErr: ErrZoneMoveCycle},
+		{name: "root into its subtree", userID: 1, zoneID: 1, parentID: 5, wantErr: ErrZoneMoveCycle},
+		{name: "to the current parent", userID: 1, zoneID: 3, parentID: 2},
+		{name: "unknown parent", userID: 1, zoneID: 2, parentID: 99, wantErr: ErrZoneNotFound},
+		{
+			name:    "without move on the zone",
+			prepare: func(s *zoneStore) { s.grant(2, s.zones[1], enums.UserEditor) },
+			userID:  2, zoneID: 3, parentID: 5,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "without create_child on the parent",
+			prepare: func(s *zoneStore) {
+				s.addRole("mover", enums.ZoneActionRead, enums.ZoneActionMove)
+				s.grant(2, s.zones[2], "mover")
+				s.grant(2, s.zones[5], enums.UserViewer)
+			},
+			userID: 2, zoneID: 3, parentID: 5,
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMoveStore()
+			if tt.prepare != nil {
+				tt.prepare(s)
+			}
+			zones, _ := s.services()
+
+			moved, err := zones.MoveZone(s.zones[tt.zoneID].UUID, tt.parentID, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if moved != tt.wantMoved {
+				t.Fatalf("moved = %d, want %d", moved, tt.wantMoved)
+			}
+			for id, path := range tt.wantPaths {
+				if got := s.zones[id].Path; got != path {
+					t.Errorf("zone %d path = %q, want %q", id, got, path)
+				}
+			}
+			if err != nil || tt.wantPaths == nil {
+				return
+			}
+			if got := *s.zones[tt.zoneID].ParentID; got != tt.parentID {
+				t.Errorf("parent = %d, want %d", got, tt.parentID)
+			}
+		})
+	}
+}
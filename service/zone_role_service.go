@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrZoneRoleNotFound  = errors.New("zone role not found")
+	ErrZoneRoleExists    = errors.New("zone role already exists")
+	ErrZoneRoleBuiltIn   = errors.New("built-in zone roles cannot be changed")
+	ErrZoneRoleInUse     = errors.New("zone role is still granted on zones")
+	ErrInvalidZoneRole   = errors.New("zone role name must be lowercase letters, digits or underscores")
+	ErrInvalidZoneAction = errors.New("invalid zone action")
+)
+
+var zoneRoleName = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// builtInZoneRoles are the roles every tenant has. They live here rather than
+// in zone_roles so they cannot drift between tenants.
+var builtInZoneRoles = []dto.ZoneRoleResponse{
+	{
+		Name:        string(enums.UserOwner),
+		Description: "Full control, including sharing and deletion",
+		Actions:     enums.ZoneActions,
+		BuiltIn:     true,
+	},
+	{
+		Name:        string(enums.UserEditor),
+		Description: "Read and edit zones and create child zones",
+		Actions: []enums.ZoneAction{
+			enums.ZoneActionRead,
+			enums.ZoneActionUpdate,
+			enums.ZoneActionCreateChild,
+			enums.ZoneActionMetadataWrite,
+		},
+		BuiltIn: true,
+	},
+	{
+		Name:        string(enums.UserViewer),
+		Description: "Read only",
+		Actions:     []enums.ZoneAction{enums.ZoneActionRead},
+		BuiltIn:     true,
+	},
+}
+
+type ZoneRoleService interface {
+	List() ([]dto.ZoneRoleResponse, error)
+	Create(req dto.CreateZoneRoleRequest) (*dto.ZoneRoleResponse, error)
+	Update(name string, req dto.UpdateZoneRoleRequest) (*dto.ZoneRoleResponse, error)
+	Delete(name string) error
+	// Actions returns what the role allows, or ErrZoneRoleNotFound.
+	Actions(role enums.UserPermission) ([]enums.ZoneAction, error)
+}
+
+type zoneRoleServiceImpl struct {
+	zoneRoleRepo repository.ZoneRoleRepo
+	userZoneRepo repository.UserZoneRepo
+}
+
+func NewZoneRoleService(zoneRoleRepo repository.ZoneRoleRepo, userZoneRepo repository.UserZoneRepo) ZoneRoleService {
+	return &zoneRoleServiceImpl{zoneRoleRepo: zoneRoleRepo, userZoneRepo: userZoneRepo}
+}
+
+func builtInZoneRole(name string) *dto.ZoneRoleResponse {
+	for i := range builtInZoneRoles {
+		if builtInZoneRoles[i].Name == name {
+			return &builtInZoneRoles[i]
+		}
+	}
+	return nil
+}
+
+func (s *zoneRoleServiceImpl) List() ([]dto.ZoneRoleResponse, error) {
+	roles, err := s.zoneRoleRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	responses := slices.Clone(builtInZoneRoles)
+	for i := range roles {
+		responses = append(responses, *convertToZoneRoleResponse(&roles[i]))
+	}
+	return responses, nil
+}
+
+func (s *zoneRoleServiceImpl) Create(req dto.CreateZoneRoleRequest) (*dto.ZoneRoleResponse, error) {
+	if !zoneRoleName.MatchString(req.Name) {
+		return nil, ErrInvalidZoneRole
+	}
+	if builtInZoneRole(req.Name) != nil {
+		return nil, ErrZoneRoleExists
+	}
+	actions, err := normalizeZoneActions(req.Actions)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.zoneRoleRepo.GetByName(req.Name); err == nil {
+		return nil, ErrZoneRoleExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	role := models.ZoneRole{
+		Name:        req.Name,
+		Description: req.Description,
+		Actions:     actions,
+	}
+	role.UUID = uuid.New().String()
+	role.CreatedAt = time.Now()
+	if err := s.zoneRoleRepo.Create(&role); err != nil {
+		return nil, err
+	}
+	return convertToZoneRoleResponse(&role), nil
+}
+
+func (s *zoneRoleServiceImpl) Update(name string, req dto.UpdateZoneRoleRequest) (*dto.ZoneRoleResponse, error) {
+	if builtInZoneRole(name) != nil {
+		return nil, ErrZoneRoleBuiltIn
+	}
+	role, err := s.zoneRoleRepo.GetByName(name)
+	if err != nil {
+		return nil, ErrZoneRoleNotFound
+	}
+	actions, err := normalizeZoneActions(req.Actions)
+	if err != nil {
+		return nil, err
+	}
+	role.Description = req.Description
+	role.Actions = actions
+	if err := s.zoneRoleRepo.Update(role); err != nil {
+		return nil, err
+	}
+	return convertToZoneRoleResponse(role), nil
+}
+
+func (s *zoneRoleServiceImpl) Delete(name string) error {
+	if builtInZoneRole(name) != nil {
+		return ErrZoneRoleBuiltIn
+	}
+	inUse, err := s.userZoneRepo.CountByPermission(enums.UserPermission(name))
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return ErrZoneRoleInUse
+	}
+	deleted, err := s.zoneRoleRepo.Delete(name)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrZoneRoleNotFound
+	}
+	return nil
+}
+
+func (s *zoneRoleServiceImpl) Actions(role enums.UserPermission) ([]enums.ZoneAction, error) {
+	if builtIn := builtInZoneRole(string(role)); builtIn != nil {
+		return builtIn.Actions, nil
+	}
+	custom, err := s.zoneRoleRepo.GetByName(string(role))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrZoneRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return custom.Actions, nil
+}
+
+// normalizeZoneActions validates the actions and drops duplicates, keeping
+// them in the order of enums.ZoneActions.
+func normalizeZoneActions(actions []enums.ZoneAction) ([]enums.ZoneAction, error) {
+	for _, action := range actions {
+		if !action.IsValid() {
+			return nil, ErrInvalidZoneAction
+		}
+	}
+	normalized := make([]enums.ZoneAction, 0, len(actions))
+	for _, action := range enums.ZoneActions {
+		if slices.Contains(actions, action) {
+			normalized = append(normalized, action)
+		}
+	}
+	return normalized, nil
+}
+
+func convertToZoneRoleResponse(role *models.ZoneRole) *dto.ZoneRoleResponse {
+	return &dto.ZoneRoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Actions:     role.Actions,
+	}
+}
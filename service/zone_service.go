@@ -1,11 +1,15 @@
 package service
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"golang-rest-user/dto"
 	"golang-rest-user/enums"
 	"golang-rest-user/models"
 	"golang-rest-user/repository"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,34 +17,90 @@ import (
 
 type ZoneService interface {
 	CreateZone(request *dto.ZoneDTORequest, userID uint) (*dto.ZoneDTOResponse, error)
-	UpdateZone(request *dto.ZoneDTORequest, uuid string) (*dto.ZoneDTOResponse, error)
-	GetUserZones(userID uint) ([]dto.ZoneDTOResponse, error)
-	DeleteZones(uuid string) (int64, error)
+	GetZone(uuid string, userID uint, withBreadcrumb bool) (*dto.ZoneDTOResponse, error)
+	GetAncestors(uuid string, userID uint) ([]dto.ZoneDTOResponse, error)
+	GetZoneTree(uuid string, userID uint, opts dto.ZoneTreeOptions) (dto.ZoneTreeNode, error)
+	UpdateZone(request *dto.ZoneDTORequest, uuid string, userID uint) (*dto.ZoneDTOResponse, error)
+	MoveZone(uuid string, parentID uint, userID uint) (int64, error)
+	GetUserZones(userID uint, page, pageSize int) ([]dto.ZoneDTOResponse, int64, error)
+	DeleteZones(uuid string, userID uint) (int64, error)
 	GetSharedZone(userID uint) ([]dto.ZoneDTOResponse, error)
+	GetUserZonesSummary(userID uint) (*dto.UserZonesSummary, error)
+	ListTrash(userID uint, page, pageSize int) ([]dto.TrashedZoneResponse, int64, error)
+	RestoreZone(uuid string, userID uint) (int64, error)
+	PurgeZone(uuid string, userID uint) (int64, error)
+	PurgeExpiredTrash(before time.Time) (int64, error)
 }
 
+var (
+	ErrZoneMoveCycle    = repository.ErrZoneCycle
+	ErrZoneParentChange = errors.New("parent_id cannot be changed by an update, move the zone instead")
+)
+
 type zoneServiceImpl struct {
 	zoneRepo     repository.ZoneRepo
 	userZoneRepo repository.UserZoneRepo
+	trashRepo    repository.ZoneTrashRepo
+	authorizer   ZoneAuthorizer
+	types        ZoneTypeService
 }
 
-func (s *zoneServiceImpl) DeleteZones(uuid string) (int64, error) {
+// authorizedZone loads a zone by UUID and checks the user may do action on it.
+func (s *zoneServiceImpl) authorizedZone(uuid string, userID uint, action enums.ZoneAction) (*models.Zone, error) {
 	zone, err := s.zoneRepo.GetByUUID(uuid)
+	if err != nil {
+		return nil, ErrZoneNotFound
+	}
+	if err := s.authorizer.Authorize(userID, zone, action); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// authorizedParent is authorizedZone for a parent given by ID.
+func (s *zoneServiceImpl) authorizedParent(id uint, userID uint) (*models.Zone, error) {
+	zone, err := s.zoneRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrZoneNotFound
+	}
+	if err := s.authorizer.Authorize(userID, zone, enums.ZoneActionCreateChild); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (s *zoneServiceImpl) GetZone(uuid string, userID uint, withBreadcrumb bool) (*dto.ZoneDTOResponse, error) {
+	zone, err := s.authorizedZone(uuid, userID, enums.ZoneActionRead)
+	if err != nil {
+		return nil, err
+	}
+	zoneResponse := convertToZoneDTOResponse(zone)
+	if withBreadcrumb {
+		if zoneResponse.Breadcrumb, err = s.breadcrumb(zone, userID); err != nil {
+			return nil, err
+		}
+	}
+	return zoneResponse, nil
+}
+
+func (s *zoneServiceImpl) DeleteZones(uuid string, userID uint) (int64, error) {
+	zone, err := s.authorizedZone(uuid, userID, enums.ZoneActionDelete)
 	if err != nil {
 		return 0, err
 	}
-	return s.zoneRepo.DeleteByPath(zone.Path)
+	return s.trashRepo.Trash(zone, userID)
 }
 
 func (s *zoneServiceImpl) CreateZone(request *dto.ZoneDTORequest, userID uint) (*dto.ZoneDTOResponse, error) {
+	var parentZone *models.Zone
 	var parentPath string
 	var parentLevel int
 	//if _, err := s.zoneRepo.GetByName(request.Name); err == nil {
 	//	return nil, fmt.Errorf("zone with name %s already exists", request.Name)
 	//}
 	if request.ParentID != nil {
-
-		parentZone, err := s.zoneRepo.GetByID(*request.ParentID)
+		var err error
+		parentZone, err = s.authorizedParent(*request.ParentID, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -54,6 +114,13 @@ func (s *zoneServiceImpl) CreateZone(request *dto.ZoneDTORequest, userID uint) (
 		ParentID: request.ParentID,
 		Level:    parentLevel + 1,
 	}
+	registry, err := s.types.Registry()
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.Check(&newZone, parentZone, true); err != nil {
+		return nil, err
+	}
 	newZone.UUID = uuid.New().String()
 	newZone.CreatedAt = time.Now()
 	if err := s.zoneRepo.Create(&newZone); err != nil {
@@ -82,37 +149,171 @@ func (s *zoneServiceImpl) CreateZone(request *dto.ZoneDTORequest, userID uint) (
 	}
 	return convertToZoneDTOResponse(&newZone), nil
 }
-func (s *zoneServiceImpl) UpdateZone(request *dto.ZoneDTORequest, uuid string) (*dto.ZoneDTOResponse, error) {
-	zone, err := s.zoneRepo.GetByUUID(uuid)
+func (s *zoneServiceImpl) UpdateZone(request *dto.ZoneDTORequest, uuid string, userID uint) (*dto.ZoneDTOResponse, error) {
+	zone, err := s.authorizedZone(uuid, userID, enums.ZoneActionUpdate)
 	if err != nil {
 		return nil, err
 	}
+	if !bytes.Equal(zone.Metadata, request.Metadata) {
+		if err := s.authorizer.Authorize(userID, zone, enums.ZoneActionMetadataWrite); err != nil {
+			return nil, err
+		}
+	}
+	if request.ParentID != nil && (zone.ParentID == nil || *zone.ParentID != *request.ParentID) {
+		return nil, ErrZoneParentChange
+	}
+	typeChanged := zone.Type != request.Type
 	zone.Name = request.Name
 	zone.Type = request.Type
 	zone.Metadata = request.Metadata
-	if request.ParentID != nil {
-		parentZone, _ := s.zoneRepo.GetByID(*request.ParentID)
-		zone.ParentID = request.ParentID
-		zone.Path = fmt.Sprintf("%s%d/", parentZone.Path, zone.ID)
-		zone.Level = parentZone.Level + 1
+	if err := s.checkType(zone, typeChanged); err != nil {
+		return nil, err
 	}
 	if err := s.zoneRepo.Update(zone); err != nil {
 		return nil, err
 	}
 	return convertToZoneDTOResponse(zone), nil
 }
-func (s *zoneServiceImpl) GetUserZones(userID uint) ([]dto.ZoneDTOResponse, error) {
-	zoneID, err := s.userZoneRepo.GetZoneID(userID)
-	zone, _ := s.zoneRepo.GetByID(zoneID)
+
+// MoveZone puts the zone and its subtree under another parent. The caller
+// must be allowed to move the zone and to create children under the parent.
+func (s *zoneServiceImpl) MoveZone(uuid string, parentID uint, userID uint) (int64, error) {
+	zone, err := s.authorizedZone(uuid, userID, enums.ZoneActionMove)
+	if err != nil {
+		return 0, err
+	}
+	parent, err := s.authorizedParent(parentID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if strings.HasPrefix(parent.Path, zone.Path) {
+		return 0, ErrZoneMoveCycle
+	}
+	if zone.ParentID != nil && *zone.ParentID == parent.ID {
+		return 0, nil
+	}
+	if err := s.checkMove(zone, parent); err != nil {
+		return 0, err
+	}
+	return s.zoneRepo.MoveSubtree(zone.ID, parent.ID)
+}
+
+// checkType validates an updated zone against its parent and, when its type
+// changed, that its children may stay under it.
+func (s *zoneServiceImpl) checkType(zone *models.Zone, typeChanged bool) error {
+	registry, err := s.types.Registry()
+	if err != nil {
+		return err
+	}
+	var parent *models.Zone
+	if zone.ParentID != nil {
+		if parent, err = s.zoneRepo.GetByID(*zone.ParentID); err != nil {
+			return err
+		}
+	}
+	if err := registry.Check(zone, parent, true); err != nil {
+		return err
+	}
+	if !typeChanged {
+		return nil
+	}
+	subZones, err := s.zoneRepo.GetSubtreeToLevel(zone.Path, zone.Level+1, false)
+	if err != nil {
+		return err
+	}
+	children := slices.DeleteFunc(subZones, func(z models.Zone) bool { return z.ID == zone.ID })
+	return registry.CheckChildren(zone, children)
+}
+
+// checkMove validates the subtree of zone at its place under parent: the
+// zone itself against the parent, and every zone at its new level.
+func (s *zoneServiceImpl) checkMove(zone, parent *models.Zone) error {
 	subZones, err := s.zoneRepo.GetSubtreeByPath(zone.Path)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	return s.checkSubtree(subZones, parent, parent.Level+1-zone.Level)
+}
+
+// checkSubtree validates zones, a subtree ordered by level, with its root
+// under parent and every zone shifted by delta levels. As in CheckChildren,
+// zones below the root without a registered type are skipped.
+func (s *zoneServiceImpl) checkSubtree(zones []models.Zone, parent *models.Zone, delta int) error {
+	registry, err := s.types.Registry()
+	if err != nil || !registry.Enforced() || len(zones) == 0 {
+		return err
+	}
+	root := zones[0].ID
+	placed := make(map[uint]*models.Zone, len(zones))
+	var errs []dto.FieldError
+	for i := range zones {
+		z := &zones[i]
+		z.Level += delta
+		placed[z.ID] = z
+		zoneParent := parent
+		if z.ID != root {
+			if _, ok := registry.types[z.Type]; !ok {
+				continue
+			}
+			zoneParent = placed[*z.ParentID]
+		}
+		for _, e := range registry.check(z, zoneParent, false) {
+			if z.ID != root {
+				e.Message = fmt.Sprintf("zone %q: %s", z.Name, e.Message)
+			}
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) > 0 {
+		return &ZoneValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// GetUserZones lists every tree the user owns or has been shared, one page of
+// trees at a time. A grant inside another granted tree does not start a tree
+// of its own, and every zone carries the role that applies to it, i.e. that
+// of the nearest grant above it.
+func (s *zoneServiceImpl) GetUserZones(userID uint, page, pageSize int) ([]dto.ZoneDTOResponse, int64, error) {
+	grants, err := s.userZoneRepo.ListGrants(userID)
+	if err != nil {
+		return nil, 0, err
 	}
+	// grants come ordered by path, so a subtree follows its root directly
+	var roots []repository.ZoneGrant
+	for _, g := range grants {
+		if len(roots) > 0 && strings.HasPrefix(g.Path, roots[len(roots)-1].Path) {
+			continue
+		}
+		roots = append(roots, g)
+	}
+	total := int64(len(roots))
+
 	zoneResponses := make([]dto.ZoneDTOResponse, 0)
-	for _, z := range subZones {
-		zoneResponses = append(zoneResponses, *convertToZoneDTOResponse(&z))
+	from := (page - 1) * pageSize
+	if from >= len(roots) {
+		return zoneResponses, total, nil
 	}
-	return zoneResponses, nil
+	roots = roots[from:min(from+pageSize, len(roots))]
+
+	readable := s.readableZones(grants)
+	for _, root := range roots {
+		subZones, err := s.zoneRepo.GetSubtreeByPath(root.Path)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range subZones {
+			if canRead, err := readable(subZones[i].Path); err != nil {
+				return nil, 0, err
+			} else if !canRead {
+				continue
+			}
+			zoneResponse := convertToZoneDTOResponse(&subZones[i])
+			zoneResponse.Permission = effectivePermission(grants, subZones[i].Path)
+			zoneResponses = append(zoneResponses, *zoneResponse)
+		}
+	}
+	return zoneResponses, total, nil
 }
 
 func (s *zoneServiceImpl) GetSharedZone(userID uint) ([]dto.ZoneDTOResponse, error) {
@@ -122,14 +323,64 @@ func (s *zoneServiceImpl) GetSharedZone(userID uint) ([]dto.ZoneDTOResponse, err
 		return nil, err
 	}
 	for _, uz := range userZones {
-		zone, _ := s.zoneRepo.GetByID(uz.ZoneID)
+		zone, err := s.zoneRepo.GetByID(uz.ZoneID)
+		if err != nil {
+			continue
+		}
+		if err := s.authorizer.Authorize(userID, zone, enums.ZoneActionRead); errors.Is(err, ErrPermissionDenied) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
 		zoneResponses = append(zoneResponses, *convertToZoneDTOResponse(zone))
 	}
 	return zoneResponses, nil
 }
 
-func NewZoneService(zoneRepo repository.ZoneRepo, userZoneRepo repository.UserZoneRepo) ZoneService {
-	return &zoneServiceImpl{zoneRepo: zoneRepo, userZoneRepo: userZoneRepo}
+func (s *zoneServiceImpl) GetUserZonesSummary(userID uint) (*dto.UserZonesSummary, error) {
+	grants, err := s.userZoneRepo.ListGrants(userID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &dto.UserZonesSummary{
+		SharedByPermission: map[string]int{},
+		Owned:              []dto.ZoneGrantSummary{},
+		Shared:             []dto.ZoneGrantSummary{},
+	}
+	for _, g := range grants {
+		item := dto.ZoneGrantSummary{
+			UUID:        g.UUID,
+			Name:        g.Name,
+			Type:        g.Type,
+			Permission:  string(g.Permission),
+			Descendants: g.Descendants,
+		}
+		if g.Permission == enums.UserOwner {
+			summary.Owned = append(summary.Owned, item)
+			continue
+		}
+		summary.Shared = append(summary.Shared, item)
+		summary.SharedByPermission[string(g.Permission)]++
+	}
+	summary.OwnedCount = len(summary.Owned)
+	summary.SharedCount = len(summary.Shared)
+	return summary, nil
+}
+
+func NewZoneService(
+	zoneRepo repository.ZoneRepo,
+	userZoneRepo repository.UserZoneRepo,
+	trashRepo repository.ZoneTrashRepo,
+	authorizer ZoneAuthorizer,
+	types ZoneTypeService,
+) ZoneService {
+	return &zoneServiceImpl{
+		zoneRepo:     zoneRepo,
+		userZoneRepo: userZoneRepo,
+		trashRepo:    trashRepo,
+		authorizer:   authorizer,
+		types:        types,
+	}
 }
 
 func convertToZoneDTOResponse(zone *models.Zone) *dto.ZoneDTOResponse {
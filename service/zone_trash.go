@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrZoneParentMissing = repository.ErrZoneParentMissing
+
+// ListTrash lists the root zones the user owned, and what was deleted inside
+// zones they may delete in.
+func (s *zoneServiceImpl) ListTrash(userID uint, page, pageSize int) ([]dto.TrashedZoneResponse, int64, error) {
+	grants, err := s.userZoneRepo.ListGrants(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var paths []string
+	for _, g := range grants {
+		actions, err := s.authorizer.RoleActions(g.Permission)
+		if err != nil {
+			return nil, 0, err
+		}
+		if slices.Contains(actions, enums.ZoneActionDelete) {
+			paths = append(paths, g.Path)
+		}
+	}
+	items, total, err := s.trashRepo.List(userID, paths, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	responses := make([]dto.TrashedZoneResponse, 0, len(items))
+	for i := range items {
+		responses = append(responses, convertToTrashedZoneResponse(&items[i]))
+	}
+	return responses, total, nil
+}
+
+// RestoreZone validates the zones that come back against the registry as it
+// is now, at the place they have now: types may have changed or gone since
+// they were trashed, and a move may have carried them along.
+func (s *zoneServiceImpl) RestoreZone(uuid string, userID uint) (int64, error) {
+	item, err := s.trashedZone(uuid, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !item.Restorable {
+		return 0, ErrZoneParentMissing
+	}
+	restored, err := s.trashRepo.Restore(item, func(zones []models.Zone, parent *models.Zone) error {
+		return s.checkSubtree(zones, parent, 0)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrZoneNotFound
+	}
+	return restored, err
+}
+
+func (s *zoneServiceImpl) PurgeZone(uuid string, userID uint) (int64, error) {
+	item, err := s.trashedZone(uuid, userID)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := s.trashRepo.Purge(item)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrZoneNotFound
+	}
+	return purged, err
+}
+
+// PurgeExpiredTrash deletes for good everything trashed before the cutoff.
+func (s *zoneServiceImpl) PurgeExpiredTrash(before time.Time) (int64, error) {
+	items, err := s.trashRepo.ListExpired(before)
+	if err != nil {
+		return 0, err
+	}
+	var purged int64
+	for i := range items {
+		n, err := s.trashRepo.Purge(&items[i])
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// restored or purged with its parent meanwhile
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged += n
+	}
+	return purged, nil
+}
+
+// trashedZone loads a trash entry the user may restore or purge: a root zone
+// they owned, or one whose parent they may currently delete zones in.
+func (s *zoneServiceImpl) trashedZone(uuid string, userID uint) (*repository.TrashItem, error) {
+	item, err := s.trashRepo.Get(uuid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrZoneNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if item.ParentID == nil {
+		owner, err := s.trashRepo.IsOwner(item, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !owner {
+			return nil, ErrPermissionDenied
+		}
+		return item, nil
+	}
+	// the parent may be in the trash too, its path still finds the grants
+	// held on the live zones above it
+	parent := &models.Zone{Path: strings.TrimSuffix(item.Path, strconv.FormatUint(uint64(item.ZoneID), 10)+"/")}
+	if err := s.authorizer.Authorize(userID, parent, enums.ZoneActionDelete); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func convertToTrashedZoneResponse(item *repository.TrashItem) dto.TrashedZoneResponse {
+	return dto.TrashedZoneResponse{
+		UUID:       item.UUID,
+		Name:       item.Name,
+		Type:       item.Type,
+		Path:       item.Path,
+		ParentID:   item.ParentID,
+		DeletedBy:  item.DeletedBy,
+		TrashedAt:  item.TrashedAt,
+		Zones:      item.Zones,
+		Restorable: item.Restorable,
+	}
+}
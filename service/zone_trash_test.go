@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/enums"
+	"testing"
+	"time"
+)
+
+// The tree of the trash tests, owned by user 1. User 2 may delete zones
+// under 1 without owning it:
+//
+//	1
+//	└── 2
+//	    └── 3
+func newTrashStore() *zoneStore {
+	s := newZoneStore()
+	root := s.addZone(1, nil)
+	a := s.addZone(2, root)
+	s.addZone(3, a)
+	s.grant(1, root, enums.UserOwner)
+	s.addRole("janitor", enums.ZoneActionRead, enums.ZoneActionDelete)
+	s.grant(2, root, "janitor")
+	s.grant(3, a, enums.UserViewer)
+	return s
+}
+
+func TestRestoreZone(t *testing.T) {
+	tests := []struct {
+		name string
+		// trash is deleted in order, [deleting user, zone]
+		trash        [][2]uint
+		prepare      func(s *zoneStore)
+		userID       uint
+		zoneID       uint
+		wantErr      error
+		wantRestored int64
+	}{
+		{name: "subtree by the owner", trash: [][2]uint{{1, 2}}, userID: 1, zoneID: 2, wantRestored: 2},
+		{name: "subtree by another deleter of the parent", trash: [][2]uint{{1, 2}}, userID: 2, zoneID: 2, wantRestored: 2},
+		{
+			name:    "by the deleter after their rights are revoked",
+			trash:   [][2]uint{{2, 2}},
+			prepare: func(s *zoneStore) { s.grantOf(2, 1).Permission = enums.UserViewer },
+			userID:  2, zoneID: 2,
+			wantErr: ErrPermissionDenied,
+		},
+		{name: "without delete on the parent", trash: [][2]uint{{1, 3}}, userID: 3, zoneID: 3, wantErr: ErrPermissionDenied},
+		{name: "root by its owner", trash: [][2]uint{{2, 1}}, userID: 1, zoneID: 1, wantRestored: 3},
+		{name: "root by its deleter", trash: [][2]uint{{2, 1}}, userID: 2, zoneID: 1, wantErr: ErrPermissionDenied},
+		{name: "with the parent in the trash", trash: [][2]uint{{1, 3}, {1, 2}}, userID: 1, zoneID: 3, wantErr: ErrZoneParentMissing},
+		{name: "live zone", userID: 1, zoneID: 2, wantErr: ErrZoneNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTrashStore()
+			zones, _ := s.services()
+			for _, d := range tt.trash {
+				if _, err := zones.DeleteZones(s.zones[d[1]].UUID, d[0]); err != nil {
+					t.Fatalf("delete zone %d: %v", d[1], err)
+				}
+			}
+			if tt.prepare != nil {
+				tt.prepare(s)
+			}
+
+			restored, err := zones.RestoreZone(s.zones[tt.zoneID].UUID, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if restored != tt.wantRestored {
+				t.Fatalf("restored = %d, want %d", restored, tt.wantRestored)
+			}
+			if err != nil {
+				return
+			}
+			for id, zone := range s.zones {
+				if zone.DeletedAt.Valid {
+					t.Errorf("zone %d still trashed", id)
+				}
+			}
+			for _, g := range s.grants {
+				if g.DeletedAt.Valid {
+					t.Errorf("grant of user %d on zone %d still trashed", g.UserID, g.ZoneID)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreZoneKeepsEarlierTrash(t *testing.T) {
+	s := newTrashStore()
+	zones, _ := s.services()
+	if _, err := zones.DeleteZones(s.zones[3].UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zones.DeleteZones(s.zones[2].UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored, err := zones.RestoreZone(s.zones[2].UUID, 1); err != nil || restored != 1 {
+		t.Fatalf("RestoreZone = %d, %v, want 1 zone", restored, err)
+	}
+	if !s.zones[3].DeletedAt.Valid {
+		t.Fatal("zone 3 was trashed on its own and should stay in the trash")
+	}
+	if restored, err := zones.RestoreZone(s.zones[3].UUID, 1); err != nil || restored != 1 {
+		t.Fatalf("RestoreZone = %d, %v, want 1 zone", restored, err)
+	}
+}
+
+func TestPurgeZone(t *testing.T) {
+	tests := []struct {
+		name       string
+		trashBy    uint
+		zoneID     uint
+		userID     uint
+		wantErr    error
+		wantPurged int64
+	}{
+		{name: "subtree by a deleter of the parent", trashBy: 1, zoneID: 2, userID: 2, wantPurged: 2},
+		{name: "subtree without delete on the parent", trashBy: 1, zoneID: 2, userID: 3, wantErr: ErrPermissionDenied},
+		{name: "root by its owner", trashBy: 2, zoneID: 1, userID: 1, wantPurged: 3},
+		{name: "root by its deleter", trashBy: 2, zoneID: 1, userID: 2, wantErr: ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTrashStore()
+			zones, _ := s.services()
+			uuid := s.zones[tt.zoneID].UUID
+			if _, err := zones.DeleteZones(uuid, tt.trashBy); err != nil {
+				t.Fatal(err)
+			}
+
+			purged, err := zones.PurgeZone(uuid, tt.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if purged != tt.wantPurged {
+				t.Fatalf("purged = %d, want %d", purged, tt.wantPurged)
+			}
+			if err != nil {
+				return
+			}
+			if _, ok := s.zones[tt.zoneID]; ok {
+				t.Error("zone is still stored")
+			}
+			for _, g := range s.grants {
+				if _, ok := s.zones[g.ZoneID]; !ok {
+					t.Errorf("grant of user %d on purged zone %d is left", g.UserID, g.ZoneID)
+				}
+			}
+			if _, err := zones.RestoreZone(uuid, 1); !errors.Is(err, ErrZoneNotFound) {
+				t.Errorf("restore after purge: err = %v, want %v", err, ErrZoneNotFound)
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	s := newTrashStore()
+	other := s.addZone(4, nil)
+	s.grant(1, other, enums.UserOwner)
+	zones, _ := s.services()
+	if _, err := zones.DeleteZones(s.zones[2].UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zones.DeleteZones(other.UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+	s.trash[1].TrashedAt = time.Now().Add(-48 * time.Hour)
+
+	purged, err := zones.PurgeExpiredTrash(time.Now().Add(-24 * time.Hour))
+	if err != nil || purged != 2 {
+		t.Fatalf("PurgeExpiredTrash = %d, %v, want 2 zones", purged, err)
+	}
+	if _, ok := s.zones[2]; ok {
+		t.Error("expired zone 2 is still stored")
+	}
+	if restored, err := zones.RestoreZone(other.UUID, 1); err != nil || restored != 1 {
+		t.Errorf("RestoreZone of the recent entry = %d, %v, want 1 zone", restored, err)
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultZoneTreeDepth = 3
+	MaxZoneTreeDepth     = 10
+)
+
+var ErrInvalidZoneField = errors.New("unknown zone field")
+
+// zoneTreeFields are the fields a tree node can carry besides uuid, which is
+// always there. Metadata is asked for separately.
+var zoneTreeFields = []string{"id", "name", "type", "path", "level", "parent_id", "created_at", "updated_at"}
+
+// GetZoneTree nests the zone's subtree down to opts.Depth levels below it.
+// Zones the user may not read are left out, and everything below them too.
+func (s *zoneServiceImpl) GetZoneTree(uuid string, userID uint, opts dto.ZoneTreeOptions) (dto.ZoneTreeNode, error) {
+	fields := zoneTreeFields
+	if len(opts.Fields) > 0 {
+		fields = nil
+		for _, field := range opts.Fields {
+			field = strings.TrimSpace(field)
+			if field == "metadata" {
+				opts.IncludeMetadata = true
+				continue
+			}
+			if !slices.Contains(zoneTreeFields, field) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidZoneField, field)
+			}
+			fields = append(fields, field)
+		}
+	}
+	if opts.Lazy {
+		opts.Depth = 1
+	}
+	if opts.Depth < 0 || opts.Depth > MaxZoneTreeDepth {
+		return nil, fmt.Errorf("depth must be between 0 and %d", MaxZoneTreeDepth)
+	}
+
+	root, err := s.authorizedZone(uuid, userID, enums.ZoneActionRead)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := s.zoneRepo.GetSubtreeToLevel(root.Path, root.Level+opts.Depth, opts.IncludeMetadata)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.userZoneRepo.ListGrants(userID)
+	if err != nil {
+		return nil, err
+	}
+	readable := s.readableZones(grants)
+
+	var childCounts map[uint]int64
+	if opts.Lazy {
+		if childCounts, err = s.readableChildCounts(zones, grants, readable); err != nil {
+			return nil, err
+		}
+	}
+
+	nodes := make(map[uint]dto.ZoneTreeNode, len(zones))
+	var tree dto.ZoneTreeNode
+	for i := range zones {
+		zone := &zones[i]
+		var parent dto.ZoneTreeNode
+		if zone.ID != root.ID {
+			// rows come ordered by level, so the parent is already placed
+			// unless it was left out, and its subtree with it
+			if zone.ParentID == nil || nodes[*zone.ParentID] == nil {
+				continue
+			}
+			parent = nodes[*zone.ParentID]
+			if ok, err := readable(zone.Path); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+
+		node := zoneTreeNode(zone, fields, opts.IncludeMetadata)
+		if zone.Level < root.Level+opts.Depth {
+			node["children"] = []dto.ZoneTreeNode{}
+		}
+		if childCounts != nil {
+			node["child_count"] = childCounts[zone.ID]
+		}
+		nodes[zone.ID] = node
+
+		if parent == nil {
+			tree = node
+			continue
+		}
+		parent["children"] = append(parent["children"].([]dto.ZoneTreeNode), node)
+	}
+	if tree == nil {
+		return nil, ErrZoneNotFound
+	}
+	return tree, nil
+}
+
+// readableZones returns whether the user holding grants may read the zone at
+// a path, resolving each role to its actions once.
+func (s *zoneServiceImpl) readableZones(grants []repository.ZoneGrant) func(path string) (bool, error) {
+	readable := map[enums.UserPermission]bool{}
+	return func(path string) (bool, error) {
+		permission := effectivePermission(grants, path)
+		canRead, ok := readable[permission]
+		if !ok {
+			actions, err := s.authorizer.RoleActions(permission)
+			if err != nil {
+				return false, err
+			}
+			canRead = slices.Contains(actions, enums.ZoneActionRead)
+			readable[permission] = canRead
+		}
+		return canRead, nil
+	}
+}
+
+// readableChildCounts counts the children of each zone the user may read. A
+// child of a readable zone is readable unless a grant on the child itself
+// says otherwise, so those grants are all there is to take off.
+func (s *zoneServiceImpl) readableChildCounts(zones []models.Zone, grants []repository.ZoneGrant, readable func(path string) (bool, error)) (map[uint]int64, error) {
+	ids := make([]uint, len(zones))
+	byPath := make(map[string]uint, len(zones))
+	for i := range zones {
+		ids[i] = zones[i].ID
+		byPath[zones[i].Path] = zones[i].ID
+	}
+	counts, err := s.zoneRepo.CountChildren(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range grants {
+		parentID, ok := byPath[parentPath(g.Path)]
+		if !ok {
+			continue
+		}
+		if canRead, err := readable(g.Path); err != nil {
+			return nil, err
+		} else if !canRead {
+			counts[parentID]--
+		}
+	}
+	return counts, nil
+}
+
+// effectivePermission is the role of the deepest grant whose zone contains path.
+func effectivePermission(grants []repository.ZoneGrant, path string) enums.UserPermission {
+	var permission enums.UserPermission
+	depth := 0
+	for _, g := range grants {
+		if len(g.Path) > depth && strings.HasPrefix(path, g.Path) {
+			permission, depth = g.Permission, len(g.Path)
+		}
+	}
+	return permission
+}
+
+// parentPath cuts the last zone off a path, "" for a root zone.
+func parentPath(path string) string {
+	trimmed := strings.TrimSuffix(path, "/")
+	return trimmed[:strings.LastIndex(trimmed, "/")+1]
+}
+
+func zoneTreeNode(zone *models.Zone, fields []string, withMetadata bool) dto.ZoneTreeNode {
+	node := dto.ZoneTreeNode{"uuid": zone.UUID}
+	for _, field := range fields {
+		switch field {
+		case "id":
+			node[field] = zone.ID
+		case "name":
+			node[field] = zone.Name
+		case "type":
+			node[field] = zone.Type
+		case "path":
+			node[field] = zone.Path
+		case "level":
+			node[field] = zone.Level
+		case "parent_id":
+			node[field] = zone.ParentID
+		case "created_at":
+			node[field] = zone.CreatedAt
+		case "updated_at":
+			node[field] = zone.UpdatedAt
+		}
+	}
+	if withMetadata {
+		node["metadata"] = zone.Metadata
+	}
+	return node
+}
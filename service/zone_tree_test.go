@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/enums"
+	"slices"
+	"testing"
+)
+
+// The tree of the tree tests, read by user 2 through a grant on zone 1:
+//
+//	1
+//	├── 2
+//	│   └── 3
+//	├── 4
+//	└── 5
+func newTreeStore() *zoneStore {
+	s := newZoneStore()
+	s.addRole("editor_only", enums.ZoneActionUpdate)
+	root := s.addZone(1, nil)
+	s.addZone(3, s.addZone(2, root))
+	s.addZone(4, root)
+	s.addZone(5, root)
+	s.grant(2, root, enums.UserViewer)
+	return s
+}
+
+// childUUIDs returns the uuids of the node's children.
+func childUUIDs(node dto.ZoneTreeNode) []string {
+	var uuids []string
+	for _, child := range node["children"].([]dto.ZoneTreeNode) {
+		uuids = append(uuids, child["uuid"].(string))
+	}
+	return uuids
+}
+
+func TestGetZoneTreeLeavesOutUnreadableZones(t *testing.T) {
+	s := newTreeStore()
+	s.grant(2, s.zones[2], "editor_only")
+	s.grant(2, s.zones[3], enums.UserViewer)
+	s.grant(2, s.zones[5], "editor_only")
+	zones, _ := s.services()
+
+	tree, err := zones.GetZoneTree("zone-1", 2, dto.ZoneTreeOptions{Depth: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zone 3 is readable, but it hangs off zone 2, which is not
+	if got, want := childUUIDs(tree), []string{"zone-4"}; !slices.Equal(got, want) {
+		t.Fatalf("children = %v, want %v", got, want)
+	}
+
+	// zone 3 is still readable as a tree of its own
+	if _, err := zones.GetZoneTree("zone-3", 2, dto.ZoneTreeOptions{Depth: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zones.GetZoneTree("zone-2", 2, dto.ZoneTreeOptions{Depth: 1}); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("err = %v, want %v", err, ErrPermissionDenied)
+	}
+}
+
+func TestGetZoneTreeLazyCountsReadableChildren(t *testing.T) {
+	s := newTreeStore()
+	s.grant(2, s.zones[5], "editor_only")
+	zones, _ := s.services()
+
+	tree, err := zones.GetZoneTree("zone-1", 2, dto.ZoneTreeOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree["child_count"]; got != int64(2) {
+		t.Errorf("child_count = %v, want 2", got)
+	}
+	if got, want := childUUIDs(tree), []string{"zone-2", "zone-4"}; !slices.Equal(got, want) {
+		t.Fatalf("children = %v, want %v", got, want)
+	}
+	if got := tree["children"].([]dto.ZoneTreeNode)[0]["child_count"]; got != int64(1) {
+		t.Errorf("child_count of zone 2 = %v, want 1", got)
+	}
+
+	// hiding the only child of zone 2 leaves it with none
+	s.grant(2, s.zones[3], "editor_only")
+	tree, err = zones.GetZoneTree("zone-1", 2, dto.ZoneTreeOptions{Lazy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree["children"].([]dto.ZoneTreeNode)[0]["child_count"]; got != int64(0) {
+		t.Errorf("child_count of zone 2 = %v, want 0", got)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"golang-rest-user/dto"
+	"golang-rest-user/jsonschema"
+	"golang-rest-user/models"
+	"golang-rest-user/repository"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrZoneTypeNotFound  = errors.New("zone type not found")
+	ErrZoneTypeExists    = errors.New("zone type already exists")
+	ErrZoneTypeInUse     = errors.New("zone type is still used by zones")
+	ErrInvalidZoneType   = errors.New("invalid zone type")
+	ErrInvalidZoneSchema = errors.New("invalid metadata_schema")
+)
+
+// ZoneValidationError lists what makes a zone invalid, field by field.
+type ZoneValidationError struct {
+	Errors []dto.FieldError
+}
+
+func (e *ZoneValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "invalid zone"
+	}
+	return fmt.Sprintf("invalid zone: %s %s", e.Errors[0].Field, e.Errors[0].Message)
+}
+
+type ZoneTypeService interface {
+	List() ([]dto.ZoneTypeResponse, error)
+	Get(name string) (*dto.ZoneTypeResponse, error)
+	Create(req dto.CreateZoneTypeRequest) (*dto.ZoneTypeResponse, error)
+	Update(name string, req dto.UpdateZoneTypeRequest) (*dto.ZoneTypeResponse, error)
+	Delete(name string) error
+	// Registry loads the current types to check zones against.
+	Registry() (*ZoneTypes, error)
+}
+
+type zoneTypeServiceImpl struct {
+	zoneTypeRepo repository.ZoneTypeRepo
+	zoneRepo     repository.ZoneRepo
+}
+
+func NewZoneTypeService(zoneTypeRepo repository.ZoneTypeRepo, zoneRepo repository.ZoneRepo) ZoneTypeService {
+	return &zoneTypeServiceImpl{zoneTypeRepo: zoneTypeRepo, zoneRepo: zoneRepo}
+}
+
+func (s *zoneTypeServiceImpl) List() ([]dto.ZoneTypeResponse, error) {
+	types, err := s.zoneTypeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.ZoneTypeResponse, 0, len(types))
+	for i := range types {
+		responses = append(responses, *convertToZoneTypeResponse(&types[i]))
+	}
+	return responses, nil
+}
+
+func (s *zoneTypeServiceImpl) Get(name string) (*dto.ZoneTypeResponse, error) {
+	zoneType, err := s.zoneTypeRepo.GetByName(name)
+	if err != nil {
+		return nil, ErrZoneTypeNotFound
+	}
+	return convertToZoneTypeResponse(zoneType), nil
+}
+
+func (s *zoneTypeServiceImpl) Create(req dto.CreateZoneTypeRequest) (*dto.ZoneTypeResponse, error) {
+	// GET /zone-types/enforcement would shadow a type of that name
+	if req.Name == "enforcement" {
+		return nil, fmt.Errorf("%w: the name %q is reserved", ErrInvalidZoneType, req.Name)
+	}
+	if _, err := s.zoneTypeRepo.GetByName(req.Name); err == nil {
+		return nil, ErrZoneTypeExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	zoneType := models.ZoneType{
+		Name:           req.Name,
+		Description:    req.Description,
+		MetadataSchema: req.MetadataSchema,
+		AllowedParents: req.AllowedParents,
+		AllowRoot:      req.AllowRoot == nil || *req.AllowRoot,
+		MaxDepth:       req.MaxDepth,
+	}
+	if err := s.validate(&zoneType); err != nil {
+		return nil, err
+	}
+	zoneType.UUID = uuid.New().String()
+	zoneType.CreatedAt = time.Now()
+	if err := s.zoneTypeRepo.Create(&zoneType); err != nil {
+		return nil, err
+	}
+	return convertToZoneTypeResponse(&zoneType), nil
+}
+
+// Update replaces the type's rules. Zones already stored are not checked
+// again until they are next changed.
+func (s *zoneTypeServiceImpl) Update(name string, req dto.UpdateZoneTypeRequest) (*dto.ZoneTypeResponse, error) {
+	zoneType, err := s.zoneTypeRepo.GetByName(name)
+	if err != nil {
+		return nil, ErrZoneTypeNotFound
+	}
+	zoneType.Description = req.Description
+	zoneType.MetadataSchema = req.MetadataSchema
+	zoneType.AllowedParents = req.AllowedParents
+	zoneType.AllowRoot = req.AllowRoot == nil || *req.AllowRoot
+	zoneType.MaxDepth = req.MaxDepth
+	if err := s.validate(zoneType); err != nil {
+		return nil, err
+	}
+	if err := s.zoneTypeRepo.Update(zoneType); err != nil {
+		return nil, err
+	}
+	return convertToZoneTypeResponse(zoneType), nil
+}
+
+func (s *zoneTypeServiceImpl) Delete(name string) error {
+	inUse, err := s.zoneRepo.CountByType(name)
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return ErrZoneTypeInUse
+	}
+	deleted, err := s.zoneTypeRepo.Delete(name)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrZoneTypeNotFound
+	}
+	return nil
+}
+
+// validate checks the schema compiles and that every allowed parent is a
+// registered type, or the type itself.
+func (s *zoneTypeServiceImpl) validate(zoneType *models.ZoneType) error {
+	if len(zoneType.MetadataSchema) > 0 {
+		if _, err := jsonschema.Compile(zoneType.MetadataSchema); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidZoneSchema, err)
+		}
+	}
+	for _, parent := range zoneType.AllowedParents {
+		if parent == zoneType.Name {
+			continue
+		}
+		if _, err := s.zoneTypeRepo.GetByName(parent); err != nil {
+			return fmt.Errorf("%w: unknown allowed parent %q", ErrInvalidZoneType, parent)
+		}
+	}
+	return nil
+}
+
+func (s *zoneTypeServiceImpl) Registry() (*ZoneTypes, error) {
+	types, err := s.zoneTypeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	registry := &ZoneTypes{types: make(map[string]*compiledZoneType, len(types))}
+	for i := range types {
+		compiled := &compiledZoneType{ZoneType: &types[i]}
+		if len(types[i].MetadataSchema) > 0 {
+			if compiled.schema, err = jsonschema.Compile(types[i].MetadataSchema); err != nil {
+				return nil, fmt.Errorf("zone type %q: %w", types[i].Name, err)
+			}
+		}
+		registry.types[types[i].Name] = compiled
+	}
+	return registry, nil
+}
+
+// ZoneTypes is a snapshot of the registry. A tenant that has not registered
+// any type keeps free-form zones; once it has, every zone needs a known type.
+type ZoneTypes struct {
+	types map[string]*compiledZoneType
+}
+
+// Enforced reports whether zones must have a registered type.
+func (t *ZoneTypes) Enforced() bool {
+	return len(t.types) > 0
+}
+
+type compiledZoneType struct {
+	*models.ZoneType
+	schema *jsonschema.Schema
+}
+
+// Check validates a zone placed under parent, nil for a root zone. Metadata
+// is only checked with checkMetadata, a move leaves it as it is.
+func (t *ZoneTypes) Check(zone, parent *models.Zone, checkMetadata bool) error {
+	if errs := t.check(zone, parent, checkMetadata); len(errs) > 0 {
+		return &ZoneValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// CheckChildren validates that children may stay under parent, e.g. after
+// its type changed. Children without a registered type are skipped: zones
+// created before the first type was registered are given one from the top
+// of their tree down.
+func (t *ZoneTypes) CheckChildren(parent *models.Zone, children []models.Zone) error {
+	var errs []dto.FieldError
+	for i := range children {
+		if _, ok := t.types[children[i].Type]; !ok {
+			continue
+		}
+		for _, e := range t.check(&children[i], parent, false) {
+			errs = append(errs, dto.FieldError{
+				Field:   "type",
+				Message: fmt.Sprintf("child zone %q: %s", children[i].Name, e.Message),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return &ZoneValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (t *ZoneTypes) check(zone, parent *models.Zone, checkMetadata bool) []dto.FieldError {
+	if !t.Enforced() {
+		return nil
+	}
+	zoneType, ok := t.types[zone.Type]
+	if !ok {
+		return []dto.FieldError{{
+			Field:   "type",
+			Message: fmt.Sprintf("unknown zone type %q, every zone needs a registered type once the tenant has any", zone.Type),
+		}}
+	}
+
+	var errs []dto.FieldError
+	switch {
+	case parent == nil && !zoneType.AllowRoot:
+		errs = append(errs, dto.FieldError{
+			Field:   "parent_id",
+			Message: fmt.Sprintf("a zone of type %q needs a parent", zone.Type),
+		})
+	case parent != nil && len(zoneType.AllowedParents) > 0 && !slices.Contains(zoneType.AllowedParents, parent.Type):
+		errs = append(errs, dto.FieldError{
+			Field:   "parent_id",
+			Message: fmt.Sprintf("a zone of type %q cannot be placed under a zone of type %q", zone.Type, parent.Type),
+		})
+	}
+	if zoneType.MaxDepth > 0 && zone.Level > zoneType.MaxDepth {
+		errs = append(errs, dto.FieldError{
+			Field:   "parent_id",
+			Message: fmt.Sprintf("a zone of type %q cannot be deeper than level %d", zone.Type, zoneType.MaxDepth),
+		})
+	}
+
+	if checkMetadata && zoneType.schema != nil {
+		metadata := []byte(zone.Metadata)
+		if len(metadata) == 0 {
+			metadata = []byte("{}")
+		}
+		violations, err := zoneType.schema.Validate(metadata)
+		if err != nil {
+			errs = append(errs, dto.FieldError{Field: "metadata", Message: "must be valid JSON"})
+		}
+		for _, v := range violations {
+			field := "metadata"
+			if v.Path != "" && v.Path[0] == '[' {
+				field += v.Path
+			} else if v.Path != "" {
+				field += "." + v.Path
+			}
+			errs = append(errs, dto.FieldError{Field: field, Message: v.Message})
+		}
+	}
+	return errs
+}
+
+func convertToZoneTypeResponse(zoneType *models.ZoneType) *dto.ZoneTypeResponse {
+	return &dto.ZoneTypeResponse{
+		Name:           zoneType.Name,
+		Description:    zoneType.Description,
+		MetadataSchema: zoneType.MetadataSchema,
+		AllowedParents: zoneType.AllowedParents,
+		AllowRoot:      zoneType.AllowRoot,
+		MaxDepth:       zoneType.MaxDepth,
+		CreatedAt:      zoneType.CreatedAt,
+		UpdatedAt:      zoneType.UpdatedAt,
+	}
+}
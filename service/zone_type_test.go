@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"golang-rest-user/dto"
+	"golang-rest-user/models"
+	"strings"
+	"testing"
+)
+
+// newTypedStore is the trash tree with the "area" type of its zones
+// registered, at most maxDepth levels deep.
+func newTypedStore(maxDepth int) *zoneStore {
+	s := newTrashStore()
+	s.types = []models.ZoneType{{Name: "area", AllowRoot: true, MaxDepth: maxDepth}}
+	return s
+}
+
+func TestRestoreZoneChecksTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, s *zoneStore, zones ZoneService)
+		wantErr string
+	}{
+		{name: "unchanged registry"},
+		{
+			name: "type no longer registered",
+			prepare: func(_ *testing.T, s *zoneStore, _ ZoneService) {
+				s.types = append(s.types, models.ZoneType{Name: "site", AllowRoot: true})
+				s.zones[3].Type = "site"
+				s.types = s.types[:1]
+			},
+			wantErr: `type unknown zone type "site"`,
+		},
+		{
+			name: "parent type no longer allowed",
+			prepare: func(_ *testing.T, s *zoneStore, _ ZoneService) {
+				s.types[0].AllowedParents = []string{"site"}
+			},
+			wantErr: `parent_id a zone of type "area" cannot be placed under a zone of type "area"`,
+		},
+		{
+			name: "carried too deep by a move",
+			prepare: func(t *testing.T, s *zoneStore, zones ZoneService) {
+				s.addZone(4, s.zones[1])
+				if _, err := zones.MoveZone(s.zones[2].UUID, 4, 1); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: `parent_id a zone of type "area" cannot be deeper than level 3`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTypedStore(3)
+			zones, _ := s.services()
+			if _, err := zones.DeleteZones(s.zones[3].UUID, 1); err != nil {
+				t.Fatal(err)
+			}
+			if tt.prepare != nil {
+				tt.prepare(t, s, zones)
+			}
+
+			restored, err := zones.RestoreZone(s.zones[3].UUID, 1)
+			if tt.wantErr == "" {
+				if err != nil || restored != 1 {
+					t.Fatalf("RestoreZone = %d, %v, want 1 zone", restored, err)
+				}
+				return
+			}
+			var invalid *ZoneValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("err = %v, want a ZoneValidationError", err)
+			}
+			var got []string
+			for _, e := range invalid.Errors {
+				got = append(got, e.Field+" "+e.Message)
+			}
+			if !strings.HasPrefix(strings.Join(got, "; "), tt.wantErr) {
+				t.Fatalf("errors = %q, want %q first", got, tt.wantErr)
+			}
+			if !s.zones[3].DeletedAt.Valid {
+				t.Error("an invalid subtree was restored")
+			}
+		})
+	}
+}
+
+func TestDeleteZoneTypeUsedInTrash(t *testing.T) {
+	s := newTypedStore(0)
+	zones, _ := s.services()
+	types := NewZoneTypeService(&fakeZoneTypeRepo{s: s}, &fakeZoneRepo{s: s})
+	if _, err := zones.DeleteZones(s.zones[1].UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := types.Delete("area"); !errors.Is(err, ErrZoneTypeInUse) {
+		t.Fatalf("err = %v, want %v", err, ErrZoneTypeInUse)
+	}
+	if _, err := zones.PurgeZone(s.zones[1].UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := types.Delete("area"); err != nil {
+		t.Fatalf("delete after purge: %v", err)
+	}
+}
+
+// Zones created before the first type was registered are given types from
+// the top of their tree down, their free-form children do not block it.
+func TestUpdateZoneTypesFreeFormTree(t *testing.T) {
+	s := newTrashStore()
+	s.types = []models.ZoneType{
+		{Name: "site", AllowRoot: true},
+		{Name: "building", AllowedParents: []string{"site"}},
+	}
+	zones, _ := s.services()
+	update := func(id uint, zoneType string) error {
+		_, err := zones.UpdateZone(&dto.ZoneDTORequest{Name: s.zones[id].Name, Type: zoneType, ParentID: s.zones[id].ParentID}, s.zones[id].UUID, 1)
+		return err
+	}
+
+	if err := update(2, "area"); err == nil || !strings.Contains(err.Error(), "needs a registered type once the tenant has any") {
+		t.Fatalf("keeping a free-form type: err = %v", err)
+	}
+	if err := update(1, "site"); err != nil {
+		t.Fatalf("root: %v", err)
+	}
+	if err := update(2, "building"); err != nil {
+		t.Fatalf("child: %v", err)
+	}
+	if err := update(1, "building"); err == nil {
+		t.Fatal("a building root was accepted")
+	}
+	if _, err := zones.MoveZone(s.zones[3].UUID, 1, 1); err == nil {
+		t.Fatal("a zone of unknown type was moved")
+	}
+}
+
+// Zones created before the first type was registered keep moving and
+// restoring with their typed parents until they are given a type.
+func TestMoveAndRestoreSkipUntypedZones(t *testing.T) {
+	s := newTypedStore(0)
+	s.zones[3].Type = ""
+	s.addZone(4, s.zones[1])
+	zones, _ := s.services()
+
+	if _, err := zones.MoveZone(s.zones[2].UUID, 4, 1); err != nil {
+		t.Fatalf("MoveZone: %v", err)
+	}
+	if _, err := zones.DeleteZones(s.zones[2].UUID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if restored, err := zones.RestoreZone(s.zones[2].UUID, 1); err != nil || restored != 2 {
+		t.Fatalf("RestoreZone = %d, %v, want 2 zones", restored, err)
+	}
+
+	s.zones[2].Type = ""
+	if _, err := zones.MoveZone(s.zones[2].UUID, 1, 1); err == nil {
+		t.Fatal("moved an untyped zone, want its own type checked")
+	}
+}
+
+func TestCreateZoneTypeReservedName(t *testing.T) {
+	s := newTypedStore(0)
+	types := NewZoneTypeService(&fakeZoneTypeRepo{s: s}, &fakeZoneRepo{s: s})
+
+	if _, err := types.Create(dto.CreateZoneTypeRequest{Name: "enforcement"}); !errors.Is(err, ErrInvalidZoneType) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidZoneType)
+	}
+}
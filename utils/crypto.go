@@ -90,3 +90,12 @@ func AESGCMDecrypt(cipherText string) (string, error) {
 
 	return string(plainText), nil
 }
+
+// RandomToken returns n random bytes, base64url encoded, for use in links.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "sync"
+
+// ParallelEach calls fn for every index in [0, n) with at most concurrency
+// calls running at once, and waits for all of them.
+func ParallelEach(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}